@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Tools offered to agents
+const (
+	ToolGetCell       = "get_cell"
+	ToolReachableArea = "reachable_area"
+	ToolLegalMoves    = "legal_moves"
+	ToolMakeMove      = "make_move"
+)
+
+// directionParameter is the argument schema of tools taking one of the
+// directions of the board
+func directionParameter(directions []Direction) json.RawMessage {
+	enum, _ := json.Marshal(directions)
+	return json.RawMessage(fmt.Sprintf(`{"type":"object","properties":{"direction":{"type":"string","enum":%s}},"required":["direction"]}`, enum))
+}
+
+// agentTools lets an agent inspect the board instead of reading a
+// precomputed analysis
+func agentTools(directions []Direction) []ToolSpec {
+	return []ToolSpec{
+		{
+			Name:        ToolGetCell,
+			Description: "Look up one board cell: empty, a player's head, a player's trail, or outside the board",
+			Parameters:  json.RawMessage(`{"type":"object","properties":{"row":{"type":"integer"},"col":{"type":"integer"}},"required":["row","col"]}`),
+		},
+		{
+			Name:        ToolReachableArea,
+			Description: "Count the cells you could still reach, and your options next turn, after moving in a direction",
+			Parameters:  directionParameter(directions),
+		},
+		{
+			Name:        ToolLegalMoves,
+			Description: "List the directions you can move in this turn",
+			Parameters:  json.RawMessage(`{"type":"object","properties":{}}`),
+		},
+		{
+			Name:        ToolMakeMove,
+			Description: "Make your move for this turn. This ends your turn.",
+			Parameters:  directionParameter(directions),
+		},
+	}
+}
+
+// ToolUse records one tool call made by an agent
+type ToolUse struct {
+	Name      string
+	Arguments string // JSON object
+	Result    string
+}
+
+// String renders the call for logs, e.g. get_cell({"row":1,"col":2}) → empty
+func (t ToolUse) String() string {
+	return fmt.Sprintf("%s(%s) → %s", t.Name, t.Arguments, t.Result)
+}
+
+// RunTool executes one tool call for a player. For a legal make_move it
+// returns the chosen direction, which ends the turn.
+func RunTool(game *GameState, player string, validMoves []Direction, call ToolCall) (string, Direction) {
+	var args struct {
+		Row       *int   `json:"row"`
+		Col       *int   `json:"col"`
+		Direction string `json:"direction"`
+	}
+	if len(call.Arguments) > 0 {
+		if err := json.Unmarshal(call.Arguments, &args); err != nil {
+			return fmt.Sprintf("error: invalid arguments: %v", err), ""
+		}
+	}
+	dir := Direction(strings.ToLower(strings.TrimSpace(args.Direction)))
+
+	switch call.Name {
+	case ToolGetCell:
+		if args.Row == nil || args.Col == nil {
+			return "error: row and col are required", ""
+		}
+		return describeCell(game, player, Position{*args.Row, *args.Col}), ""
+
+	case ToolReachableArea:
+		reason, blocked := getBlockedMoves(game, player, validMoves)[dir]
+		if blocked {
+			return fmt.Sprintf("%s is blocked: %s", dir, reason), ""
+		}
+		for _, validDir := range validMoves {
+			if dir == validDir {
+				eval := evaluateMove(game, game.PlayerPos[player], dir)
+				return fmt.Sprintf("moving %s to (%d,%d): %d cells reachable, %d moves available next turn",
+					dir, eval.NewPos.Row, eval.NewPos.Col, eval.ReachableTerritory, eval.ImmediateMoves), ""
+			}
+		}
+		return fmt.Sprintf("error: unknown direction %q", args.Direction), ""
+
+	case ToolLegalMoves:
+		return formatValidMoves(validMoves), ""
+
+	case ToolMakeMove:
+		for _, validDir := range validMoves {
+			if dir == validDir {
+				return "ok, moved " + string(dir), dir
+			}
+		}
+		if reason, blocked := getBlockedMoves(game, player, validMoves)[dir]; blocked {
+			return fmt.Sprintf("error: %s is blocked (%s); legal moves: %s", dir, reason, formatValidMoves(validMoves)), ""
+		}
+		return fmt.Sprintf("error: unknown direction %q; legal moves: %s", args.Direction, formatValidMoves(validMoves)), ""
+	}
+
+	return fmt.Sprintf("error: unknown tool %q", call.Name), ""
+}
+
+// describeCell tells an agent what occupies a cell
+func describeCell(game *GameState, player string, pos Position) string {
+	pos = game.Wrap(pos)
+	if !game.InBounds(pos) {
+		return "outside the board"
+	}
+	cell := game.Grid[pos.Row][pos.Col]
+	switch cell {
+	case Empty:
+		return "empty"
+	case Wall:
+		return "a wall"
+	case Blocked:
+		return "a blocked cell"
+	}
+	owner := func(id string) string {
+		if id == player {
+			return "you"
+		}
+		return "Player " + id
+	}
+	for i, trail := range TrailChars {
+		if cell == trail {
+			return "trail of " + owner(PlayerIDs[i])
+		}
+	}
+	return "head of " + owner(cell)
+}
+
+// GetAgentMove lets the model inspect the board with tools until it calls
+// make_move. After maxToolCalls calls the tools are withdrawn and the model
+// has to answer in text.
+func GetAgentMove(game *GameState, player string, validMoves []Direction, prompt string) (*LLMDecision, error) {
+	chat, ok := backend.(ChatBackend)
+	if !ok {
+		return nil, fmt.Errorf("the %s API does not support tool calling", backend.Name())
+	}
+
+	playerConfig := game.PlayerConfigs[player]
+	decision := &LLMDecision{PromptHash: hashPrompt(prompt)}
+	messages := []ChatMessage{{Role: "user", Content: prompt}}
+	memory := game.Memories[player]
+	if memory != nil {
+		messages = memory.Messages(prompt)
+	}
+	done := func() (*LLMDecision, error) {
+		if memory != nil {
+			if err := memory.Remember(playerConfig, prompt, decision.Response); err != nil {
+				return nil, err
+			}
+		}
+		return decision, nil
+	}
+
+	// Every reply either runs a tool, answers or counts as a retry, so a
+	// turn that takes more replies than this has gone wrong
+	maxReplies := maxToolCalls + maxRetries + 1
+	for replies := 0; replies < maxReplies; replies++ {
+		tools := agentTools(game.Directions())
+		if len(decision.ToolCalls) >= maxToolCalls {
+			tools = nil
+		}
+
+		start := time.Now()
+		reply, err := chat.Chat(&ChatRequest{
+			Model:       playerConfig.Model,
+			Temperature: playerConfig.Temperature,
+			Messages:    messages,
+			Tools:       tools,
+		})
+		latency := time.Since(start).Seconds()
+		decision.Latency += latency
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *reply)
+
+		if len(reply.ToolCalls) == 0 {
+			// A plain answer is accepted like in a normal turn
+			direction, thoughts, err := parseMove(reply.Content, moveTargets(game, player), validMoves, playerConfig)
+			if err == nil {
+				decision.Direction = direction
+				decision.Response = reply.Content
+				decision.Reasoning = thoughts
+				decision.Attempts = append(decision.Attempts, Attempt{Response: reply.Content, Latency: latency})
+				return done()
+			}
+
+			attempt := rejectedAttempt(reply.Content, latency, err)
+			decision.Attempts = append(decision.Attempts, attempt)
+			if isAmbiguous(err) {
+				decision.Ambiguous++
+			}
+			decision.Retries++
+			renderer.Rejected(game, player, attempt, decision.Retries)
+			if decision.Retries >= maxRetries {
+				return nil, fmt.Errorf("max retries exceeded")
+			}
+			messages = append(messages, ChatMessage{
+				Role:    "user",
+				Content: rejection(game, player, validMoves, err) + " " + agentReminder(decision, validMoves),
+			})
+			continue
+		}
+
+		for _, call := range reply.ToolCalls {
+			if len(decision.ToolCalls) >= maxToolCalls {
+				messages = append(messages, ChatMessage{
+					Role:       "tool",
+					Content:    fmt.Sprintf("error: tool call limit of %d reached, this call was not run", maxToolCalls),
+					ToolCallID: call.ID,
+				})
+				continue
+			}
+			result, direction := RunTool(game, player, validMoves, call)
+			use := ToolUse{Name: call.Name, Arguments: string(call.Arguments), Result: result}
+			decision.ToolCalls = append(decision.ToolCalls, use)
+			if debugMode {
+				renderer.Debug("", "🔧 "+use.String())
+			}
+
+			if direction != "" {
+				decision.Direction = direction
+				decision.Response = use.String()
+				decision.Reasoning = strings.TrimSpace(reply.Content)
+				decision.Attempts = append(decision.Attempts, Attempt{Response: use.String(), Latency: latency})
+				return done()
+			}
+			if call.Name == ToolMakeMove {
+				decision.Retries++
+				decision.Attempts = append(decision.Attempts, Attempt{
+					Response: use.String(),
+					Failure:  FailIllegal,
+					Error:    result,
+					Latency:  latency,
+				})
+				if decision.Retries >= maxRetries {
+					return nil, fmt.Errorf("max retries exceeded")
+				}
+			}
+			messages = append(messages, ChatMessage{Role: "tool", Content: result, ToolCallID: call.ID})
+		}
+
+		if len(decision.ToolCalls) >= maxToolCalls {
+			messages = append(messages, ChatMessage{Role: "user", Content: agentReminder(decision, validMoves)})
+		}
+	}
+	return nil, fmt.Errorf("no move after %d replies", maxReplies)
+}
+
+// agentReminder asks an agent for its final answer
+func agentReminder(decision *LLMDecision, validMoves []Direction) string {
+	if len(decision.ToolCalls) >= maxToolCalls {
+		return fmt.Sprintf("You have used all %d tool calls. Reply with exactly one word, your move: %s",
+			maxToolCalls, formatValidMoves(validMoves))
+	}
+	return fmt.Sprintf("Call make_move with your direction, or reply with exactly one word: %s",
+		formatValidMoves(validMoves))
+}
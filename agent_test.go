@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRunTool(t *testing.T) {
+	// Both players made one move on a 4x3 board with a wall:
+	//
+	//	a 1 . #
+	//	. . . .
+	//	. . 2 b
+	setup := &GameSetup{
+		Width: 4, Height: 3,
+		Walls:          []Position{{0, 3}},
+		StartPositions: []Position{{0, 0}, {2, 3}},
+		Players:        []*PlayerConfig{{Model: "a"}, {Model: "b"}},
+	}
+	game := InitGame(setup)
+	MakeMove(game, "1", Right)
+	MakeMove(game, "2", Left)
+	valid := GetValidMoves(game, "1")
+
+	tests := []struct {
+		name, args string
+		want       string
+		move       Direction
+	}{
+		{ToolGetCell, `{"row":0,"col":0}`, "trail of you", ""},
+		{ToolGetCell, `{"row":0,"col":1}`, "head of you", ""},
+		{ToolGetCell, `{"row":2,"col":2}`, "head of Player 2", ""},
+		{ToolGetCell, `{"row":2,"col":3}`, "trail of Player 2", ""},
+		{ToolGetCell, `{"row":0,"col":3}`, "a wall", ""},
+		{ToolGetCell, `{"row":1,"col":1}`, "empty", ""},
+		{ToolGetCell, `{"row":-1,"col":1}`, "outside the board", ""},
+		{ToolReachableArea, `{"direction":"Down"}`, "moving down to (1,1): 7 cells reachable, 3 moves available next turn", ""},
+		{ToolReachableArea, `{"direction":"left"}`, "left is blocked: already visited", ""},
+		{ToolReachableArea, `{"direction":"up"}`, "up is blocked: out of bounds", ""},
+		{ToolLegalMoves, ``, "down, right", ""},
+		{ToolLegalMoves, `{}`, "down, right", ""},
+		{ToolMakeMove, `{"direction":"right"}`, "ok, moved right", Right},
+		{ToolMakeMove, `{"direction":" DOWN "}`, "ok, moved down", Down},
+
+		// Bad arguments are answered with an error for the model to correct
+		{ToolGetCell, `{"row":1}`, "error: row and col are required", ""},
+		{ToolGetCell, `{"row":"one","col":1}`, "error: invalid arguments: ", ""},
+		{ToolGetCell, `[1, 2]`, "error: invalid arguments: ", ""},
+		{ToolReachableArea, `{"direction":"sideways"}`, `error: unknown direction "sideways"`, ""},
+		{ToolMakeMove, `{"direction":"left"}`, "error: left is blocked (already visited); legal moves: down, right", ""},
+		{ToolMakeMove, `{}`, `error: unknown direction ""; legal moves: down, right`, ""},
+		{"teleport", `{}`, `error: unknown tool "teleport"`, ""},
+	}
+	for _, tt := range tests {
+		call := ToolCall{Name: tt.name, Arguments: json.RawMessage(tt.args)}
+		result, move := RunTool(game, "1", valid, call)
+		if strings.HasSuffix(tt.want, ": ") && strings.HasPrefix(result, tt.want) {
+			result = tt.want
+		}
+		if result != tt.want || move != tt.move {
+			t.Errorf("%s(%s) = %q, %q; want %q, %q", tt.name, tt.args, result, move, tt.want, tt.move)
+		}
+	}
+}
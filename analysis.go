@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+// MoveLabel classifies a move against the engine's ranking
+type MoveLabel string
+
+const (
+	LabelForced     MoveLabel = "forced"     // Only one legal move
+	LabelBest       MoveLabel = "best"       // Top-ranked move (or tied with it)
+	LabelInaccuracy MoveLabel = "inaccuracy" // Not the best, but close to it
+	LabelBlunder    MoveLabel = "blunder"    // Clearly worse than the best move
+	LabelFatal      MoveLabel = "fatal"      // Enters a dead end or a far smaller region than the best move
+)
+
+// fatalTerritoryRatio is the share of the largest reachable region below
+// which entering a smaller region counts as fatal rather than a blunder
+const fatalTerritoryRatio = 0.5
+
+// blunderScoreRatio is the share of the best score below which a move in
+// the same region counts as a blunder rather than an inaccuracy
+const blunderScoreRatio = 0.8
+
+// MoveAnalysis is the verdict on one recorded move
+type MoveAnalysis struct {
+	MoveNumber int
+	Player     string
+	Model      string
+	Chosen     MoveEvaluation
+	Best       MoveEvaluation
+	Rank       int
+	Options    int
+	ChosenPath int // Longest path after the chosen move (deep search only)
+	BestPath   int // Longest path after the best alternative (deep search only)
+	Label      MoveLabel
+}
+
+// AnalyzeMove ranks the options of a player before a move and labels the
+// chosen direction. With depth > 0 every option is also searched for the
+// longest path the player could still walk, up to depth moves.
+func AnalyzeMove(game *GameState, player string, chosen Direction, depth int) MoveAnalysis {
+	validMoves := GetValidMoves(game, player)
+	evaluations := RankMoves(game, player, validMoves)
+
+	analysis := MoveAnalysis{
+		Player:  player,
+		Rank:    engineRank(evaluations, chosen),
+		Options: len(evaluations),
+	}
+	if len(evaluations) == 0 || analysis.Rank == 0 {
+		return analysis
+	}
+	analysis.Best = evaluations[0]
+	analysis.Chosen = evaluations[analysis.Rank-1]
+
+	if depth > 0 {
+		for _, eval := range evaluations {
+			length := longestPath(simulateMove(game, eval.NewPos), eval.NewPos, depth)
+			if eval.Direction == chosen {
+				analysis.ChosenPath = length
+			}
+			if length > analysis.BestPath {
+				analysis.BestPath = length
+			}
+		}
+	}
+
+	analysis.Label = labelMove(evaluations, analysis.Rank, analysis.ChosenPath, analysis.BestPath)
+	return analysis
+}
+
+// labelMove labels the move of the given 1-based rank in a ranking, best
+// first. chosenPath and bestPath are the longest paths after the chosen and
+// the best move, or zero without a deep search.
+func labelMove(evaluations []MoveEvaluation, rank, chosenPath, bestPath int) MoveLabel {
+	best, chosen := evaluations[0], evaluations[rank-1]
+
+	maxTerritory := 0
+	alternativeSurvives := false
+	for _, eval := range evaluations {
+		if eval.ReachableTerritory > maxTerritory {
+			maxTerritory = eval.ReachableTerritory
+		}
+		if eval.Direction != chosen.Direction && eval.ImmediateMoves > 0 {
+			alternativeSurvives = true
+		}
+	}
+
+	switch {
+	case len(evaluations) == 1:
+		return LabelForced
+	case rank == 1 || chosen.TotalScore >= best.TotalScore:
+		return LabelBest
+	case chosen.ImmediateMoves == 0 && alternativeSurvives:
+		return LabelFatal
+	case float64(chosen.ReachableTerritory) < float64(maxTerritory)*fatalTerritoryRatio:
+		// Territory only differs between moves that enter different regions,
+		// so the lost space can never be won back
+		return LabelFatal
+	case chosen.ReachableTerritory < maxTerritory:
+		return LabelBlunder
+	case chosenPath < bestPath:
+		return LabelBlunder
+	case chosen.TotalScore < best.TotalScore*blunderScoreRatio:
+		return LabelBlunder
+	default:
+		return LabelInaccuracy
+	}
+}
+
+// longestPath returns the length of the longest path a lone player could
+// still walk from pos, searching at most maxDepth moves ahead
+func longestPath(game *GameState, pos Position, maxDepth int) int {
+	if maxDepth == 0 {
+		return 0
+	}
+
+	best := 0
+	for _, next := range getAvailablePositions(game, pos) {
+		game.Visited[next] = true
+		if length := 1 + longestPath(game, next, maxDepth-1); length > best {
+			best = length
+		}
+		delete(game.Visited, next)
+		if best == maxDepth {
+			break
+		}
+	}
+	return best
+}
+
+// AnalyzeGame replays a recorded game and analyzes every move
+func AnalyzeGame(record *GameRecord, depth int) []MoveAnalysis {
+	game := InitGame(record.Setup)
+
+	analyses := make([]MoveAnalysis, 0, len(record.Moves))
+	for i, move := range record.Moves {
+		analysis := AnalyzeMove(game, move.Player, move.Direction, depth)
+		analysis.MoveNumber = i + 1
+		analysis.Model = record.Setup.Players[playerIndex(move.Player)].Label()
+		analyses = append(analyses, analysis)
+
+		MakeMove(game, move.Player, move.Direction)
+	}
+	return analyses
+}
+
+// AccuracySummary aggregates move labels for one model
+type AccuracySummary struct {
+	Model  string
+	Moves  int
+	Labels map[MoveLabel]int
+	Ranks  int // Sum of engine ranks of unforced moves
+}
+
+// Accuracy returns the share of unforced moves that were best
+func (s *AccuracySummary) Accuracy() float64 {
+	unforced := s.Moves - s.Labels[LabelForced]
+	if unforced == 0 {
+		return 0
+	}
+	return float64(s.Labels[LabelBest]) / float64(unforced) * 100
+}
+
+// AvgRank returns the average engine rank of unforced moves
+func (s *AccuracySummary) AvgRank() float64 {
+	unforced := s.Moves - s.Labels[LabelForced]
+	if unforced == 0 {
+		return 0
+	}
+	return float64(s.Ranks) / float64(unforced)
+}
+
+// SummarizeAccuracy groups move analyses per model, in order of appearance
+func SummarizeAccuracy(analyses []MoveAnalysis) []*AccuracySummary {
+	var summaries []*AccuracySummary
+	byModel := make(map[string]*AccuracySummary)
+	for _, analysis := range analyses {
+		summary, ok := byModel[analysis.Model]
+		if !ok {
+			summary = &AccuracySummary{Model: analysis.Model, Labels: make(map[MoveLabel]int)}
+			byModel[analysis.Model] = summary
+			summaries = append(summaries, summary)
+		}
+		summary.Moves++
+		summary.Labels[analysis.Label]++
+		if analysis.Label != LabelForced {
+			summary.Ranks += analysis.Rank
+		}
+	}
+	return summaries
+}
+
+// RunAnalyzeCommand implements the `analyze` subcommand, which labels every
+// stored move against the engine's ranking and summarizes accuracy per model
+func RunAnalyzeCommand(args []string) error {
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	path := fs.String("db", "snakes.db", "SQLite database written with -db")
+	run := fs.String("run", "", "Only include games from this run ID")
+	depth := fs.Int("depth", 0, "Also search this many moves ahead for the longest survivable path (0 to disable)")
+	out := fs.String("out", "", "Write the verdict on every move to this CSV file")
+	verbose := fs.Bool("v", false, "List every inaccuracy, blunder and fatal move")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(*path); err != nil {
+		return fmt.Errorf("database %s: %w", *path, err)
+	}
+	store, err := OpenStore(*path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	games, err := store.LoadGames(*run)
+	if err != nil {
+		return err
+	}
+
+	var csvWriter *csv.Writer
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		csvWriter = csv.NewWriter(file)
+		defer csvWriter.Flush()
+		csvWriter.Write([]string{"run_id", "game", "move", "player", "model", "direction", "label",
+			"rank", "options", "score", "best_score", "territory", "best_territory", "path", "best_path"})
+	}
+
+	var all []MoveAnalysis
+	for _, game := range games {
+		analyses := AnalyzeGame(game.Record, *depth)
+		all = append(all, analyses...)
+
+		for _, a := range analyses {
+			if *verbose && a.Label != LabelBest && a.Label != LabelForced && a.Label != "" {
+				fmt.Printf("Run %s game %d move %d: Player %s (%s) chose %s [%s] - best was %s (score %.1f vs %.1f, territory %d vs %d)\n",
+					game.RunID, game.Record.Number, a.MoveNumber, a.Player, a.Model, a.Chosen.Direction,
+					a.Label, a.Best.Direction, a.Chosen.TotalScore, a.Best.TotalScore,
+					a.Chosen.ReachableTerritory, a.Best.ReachableTerritory)
+			}
+			if csvWriter != nil {
+				csvWriter.Write([]string{game.RunID, strconv.Itoa(game.Record.Number), strconv.Itoa(a.MoveNumber),
+					a.Player, a.Model, string(a.Chosen.Direction), string(a.Label), strconv.Itoa(a.Rank),
+					strconv.Itoa(a.Options), fmt.Sprintf("%.1f", a.Chosen.TotalScore), fmt.Sprintf("%.1f", a.Best.TotalScore),
+					strconv.Itoa(a.Chosen.ReachableTerritory), strconv.Itoa(a.Best.ReachableTerritory),
+					strconv.Itoa(a.ChosenPath), strconv.Itoa(a.BestPath)})
+			}
+		}
+	}
+
+	fmt.Printf("Analyzed %d moves in %d games\n\n", len(all), len(games))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tMOVES\tFORCED\tBEST\tINACCURACY\tBLUNDER\tFATAL\tACCURACY\tAVG RANK")
+	for _, s := range SummarizeAccuracy(all) {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%.1f%%\t%.2f\n", s.Model, s.Moves,
+			s.Labels[LabelForced], s.Labels[LabelBest], s.Labels[LabelInaccuracy],
+			s.Labels[LabelBlunder], s.Labels[LabelFatal], s.Accuracy(), s.AvgRank())
+	}
+	return w.Flush()
+}
@@ -0,0 +1,73 @@
+package main
+
+import "testing"
+
+func TestLabelMove(t *testing.T) {
+	eval := func(dir Direction, territory, moves int, score float64) MoveEvaluation {
+		return MoveEvaluation{Direction: dir, ReachableTerritory: territory, ImmediateMoves: moves, TotalScore: score}
+	}
+	tests := []struct {
+		name                 string
+		evaluations          []MoveEvaluation
+		rank                 int
+		chosenPath, bestPath int
+		want                 MoveLabel
+	}{
+		{"only move", []MoveEvaluation{eval(Up, 1, 0, 5)}, 1, 0, 0, LabelForced},
+		{"top ranked", []MoveEvaluation{eval(Up, 20, 3, 60), eval(Down, 20, 2, 55)}, 1, 0, 0, LabelBest},
+		{"tied with the top", []MoveEvaluation{eval(Up, 20, 3, 60), eval(Down, 20, 3, 60)}, 2, 0, 0, LabelBest},
+		{"dead end", []MoveEvaluation{eval(Up, 20, 3, 60), eval(Down, 20, 0, 50)}, 2, 0, 0, LabelFatal},
+		{"under half the space", []MoveEvaluation{eval(Up, 20, 3, 60), eval(Down, 9, 2, 40)}, 2, 0, 0, LabelFatal},
+		{"exactly half the space", []MoveEvaluation{eval(Up, 20, 3, 60), eval(Down, 10, 2, 55)}, 2, 0, 0, LabelBlunder},
+		{"slightly less space", []MoveEvaluation{eval(Up, 20, 3, 60), eval(Down, 19, 3, 58)}, 2, 0, 0, LabelBlunder},
+		{"shorter path", []MoveEvaluation{eval(Up, 20, 3, 60), eval(Down, 20, 3, 58)}, 2, 12, 15, LabelBlunder},
+		{"far lower score", []MoveEvaluation{eval(Up, 20, 3, 60), eval(Down, 20, 1, 47)}, 2, 0, 0, LabelBlunder},
+		{"close score", []MoveEvaluation{eval(Up, 20, 3, 60), eval(Down, 20, 3, 49)}, 2, 15, 15, LabelInaccuracy},
+	}
+	for _, tt := range tests {
+		if got := labelMove(tt.evaluations, tt.rank, tt.chosenPath, tt.bestPath); got != tt.want {
+			t.Errorf("%s: labelMove = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeMove(t *testing.T) {
+	// The player sits between a three-cell pocket on the left and the open
+	// board on the right:
+	//
+	//	. # . . . . .
+	//	. 1 . . . . .
+	//	. # . . . . .
+	setup := &GameSetup{
+		Width: 7, Height: 3,
+		Walls:          []Position{{0, 1}, {2, 1}},
+		StartPositions: []Position{{1, 1}},
+		Players:        []*PlayerConfig{{Model: "a"}},
+	}
+	tests := []struct {
+		dir   Direction
+		depth int
+		want  MoveLabel
+		rank  int
+	}{
+		{Right, 0, LabelBest, 1},
+		{Left, 0, LabelFatal, 2},
+		{Left, 4, LabelFatal, 2},
+	}
+	for _, tt := range tests {
+		a := AnalyzeMove(InitGame(setup), "1", tt.dir, tt.depth)
+		if a.Label != tt.want || a.Rank != tt.rank || a.Options != 2 {
+			t.Errorf("AnalyzeMove(%s, depth %d) = %s rank %d of %d, want %s rank %d of 2",
+				tt.dir, tt.depth, a.Label, a.Rank, a.Options, tt.want, tt.rank)
+		}
+		if tt.depth > 0 && (a.ChosenPath != 1 || a.BestPath != 4) {
+			t.Errorf("AnalyzeMove(%s, depth %d): paths %d and %d, want 1 and 4", tt.dir, tt.depth, a.ChosenPath, a.BestPath)
+		}
+	}
+
+	// With the pocket blocked off, right is the only move
+	setup.Blocked = []Position{{1, 0}}
+	if a := AnalyzeMove(InitGame(setup), "1", Right, 0); a.Label != LabelForced {
+		t.Errorf("AnalyzeMove with one legal move = %s, want %s", a.Label, LabelForced)
+	}
+}
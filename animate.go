@@ -0,0 +1,500 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layout of the image exports, in pixels
+const (
+	animPad       = 10 // Margin around everything
+	animTextScale = 2  // Font pixel size of the title and legend
+	animLineGap   = 6  // Space between lines of text
+	animSwatch    = 14 // Color square in front of each legend line
+)
+
+var (
+	animBackground = "#ffffff"
+	animGridLine   = "#cccccc"
+	animText       = "#222222"
+)
+
+// animFrame is a game after a number of moves, as the image exports draw it
+type animFrame struct {
+	Cells  [][]string // Fill color of every cell, "" for empty
+	Hex    bool       // Rows shift half a cell right, see Geometry
+	Heads  []Position // Head of every player
+	Title  string
+	Legend []string // One line per player: model and state
+}
+
+// RunAnimateCommand implements the `animate` subcommand, which renders a
+// stored game as an animated GIF, an animated SVG or numbered PNG frames
+func RunAnimateCommand(args []string) error {
+	fs := flag.NewFlagSet("animate", flag.ExitOnError)
+	path := fs.String("db", "snakes.db", "SQLite database written with -db")
+	run := fs.String("run", "", "Run ID of the game (default: the latest run)")
+	number := fs.Int("game", 1, "Game number within the run")
+	out := fs.String("o", "game.gif", "File to write: .gif, .svg, or .png for numbered frames (game-000.png, ...)")
+	cell := fs.Int("cell", 24, "Cell size in pixels")
+	delay := fs.Duration("delay", 400*time.Millisecond, "Time each move is shown")
+	hold := fs.Duration("hold", 3*time.Second, "Time the final position is shown before the animation repeats")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *cell < 8 {
+		return fmt.Errorf("-cell must be at least 8 pixels")
+	}
+
+	if _, err := os.Stat(*path); err != nil {
+		return fmt.Errorf("database %s: %w", *path, err)
+	}
+	store, err := OpenStore(*path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	games, err := store.LoadGames(*run)
+	if err != nil {
+		return err
+	}
+	if len(games) == 0 {
+		return fmt.Errorf("no games found in %s", *path)
+	}
+	runID := *run
+	if runID == "" {
+		runID = games[len(games)-1].RunID
+	}
+	var record *GameRecord
+	for _, game := range games {
+		if game.RunID == runID && game.Record.Number == *number {
+			record = game.Record
+		}
+	}
+	if record == nil {
+		return fmt.Errorf("no game %d in run %s", *number, runID)
+	}
+
+	frames := gameFrames(record)
+	switch ext := strings.ToLower(filepath.Ext(*out)); ext {
+	case ".gif":
+		err = writeGIF(*out, frames, *cell, *delay, *hold)
+	case ".svg":
+		err = writeSVG(*out, frames, *cell, *delay, *hold)
+	case ".png":
+		var written []string
+		if written, err = writePNGFrames(*out, frames, *cell); err == nil {
+			fmt.Printf("Wrote %d frames of run %s game %d: %s ... %s\n",
+				len(written), runID, *number, written[0], written[len(written)-1])
+			return nil
+		}
+	default:
+		return fmt.Errorf("unknown image format %q (use .gif, .svg or .png)", ext)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %d frames of run %s game %d to %s\n", len(frames), runID, *number, *out)
+	return nil
+}
+
+// gameFrames replays a game and returns the start position and the board
+// after every move. The last frame also shows the result.
+func gameFrames(record *GameRecord) []animFrame {
+	game := InitGame(record.Setup)
+	numPlayers := len(record.Setup.Players)
+
+	// Eliminations are numbered by turn, and a turn in which a player is
+	// eliminated has no move, so find the turn of every move
+	eliminationTurns := make(map[int]bool)
+	for _, turn := range record.EliminatedAt {
+		eliminationTurns[turn] = true
+	}
+	moveTurns := make([]int, len(record.Moves))
+	turn := 0
+	for i := range record.Moves {
+		turn++
+		for eliminationTurns[turn] {
+			turn++
+		}
+		moveTurns[i] = turn
+	}
+
+	frame := func(moves int) animFrame {
+		last := moves == len(record.Moves)
+		f := animFrame{
+			Cells: make([][]string, game.Height),
+			Heads: make([]Position, numPlayers),
+			Hex:   game.Geometry == GeometryHex,
+			Title: fmt.Sprintf("Game %d - move %d/%d", record.Number, moves, len(record.Moves)),
+		}
+
+		out := make([]bool, numPlayers)
+		for i := range out {
+			at := record.EliminatedAt[PlayerIDs[i]]
+			out[i] = at > 0 && (last || at < moveTurns[moves])
+		}
+
+		for row := range f.Cells {
+			f.Cells[row] = make([]string, game.Width)
+			for col := range f.Cells[row] {
+				cell := game.Grid[row][col]
+				switch cell {
+				case Wall:
+					f.Cells[row][col] = wallColor
+				case Blocked:
+					f.Cells[row][col] = blockedColor
+				}
+				for i := 0; i < numPlayers; i++ {
+					color := PlayerColors[i%len(PlayerColors)]
+					switch {
+					case cell == PlayerIDs[i] && out[i]:
+						f.Cells[row][col] = mixColor(color, 0.6)
+					case cell == PlayerIDs[i]:
+						f.Cells[row][col] = color
+					case cell == TrailChars[i]:
+						f.Cells[row][col] = mixColor(color, 0.45)
+					}
+				}
+			}
+		}
+
+		for i := 0; i < numPlayers; i++ {
+			id := PlayerIDs[i]
+			f.Heads[i] = game.PlayerPos[id]
+			line := fmt.Sprintf("Player %s: %s", id, record.Setup.Players[i].Label())
+			switch {
+			case last && record.Winner == id:
+				line += " - winner"
+			case out[i]:
+				line += " - out"
+			}
+			f.Legend = append(f.Legend, line)
+		}
+
+		if last {
+			switch record.Winner {
+			case "":
+				f.Title += " - draw"
+			case "error":
+				f.Title += " - aborted by an error"
+			default:
+				f.Title += fmt.Sprintf(" - Player %s wins", record.Winner)
+			}
+		}
+		return f
+	}
+
+	frames := []animFrame{frame(0)}
+	for i, move := range record.Moves {
+		MakeMove(game, move.Player, move.Direction)
+		frames = append(frames, frame(i+1))
+	}
+	return frames
+}
+
+// parseHexColor reads a "#rrggbb" color
+func parseHexColor(hex string) color.RGBA {
+	value, _ := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	return color.RGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 0xff}
+}
+
+// mixColor blends a "#rrggbb" color with white, keeping a share of the color
+func mixColor(hex string, share float64) string {
+	c := parseHexColor(hex)
+	mix := func(v uint8) uint8 { return uint8(float64(v)*share + 255*(1-share) + 0.5) }
+	return fmt.Sprintf("#%02x%02x%02x", mix(c.R), mix(c.G), mix(c.B))
+}
+
+// animLayout places the title, board and legend of a frame
+type animLayout struct {
+	cell          int
+	rows, cols    int
+	hex           bool
+	width, height int // Of the whole image
+	boardX        int
+	boardY        int
+	boardWidth    int
+	boardHeight   int
+	legendY       int
+	lineHeight    int
+}
+
+func newAnimLayout(frames []animFrame, cell int) animLayout {
+	l := animLayout{
+		cell:       cell,
+		rows:       len(frames[0].Cells),
+		cols:       len(frames[0].Cells[0]),
+		hex:        frames[0].Hex,
+		lineHeight: glyphHeight*animTextScale + animLineGap,
+	}
+	l.boardWidth = l.cols*(cell+1) + 1
+	if l.hex {
+		l.boardWidth += (l.rows - 1) * (cell + 1) / 2
+	}
+	l.boardHeight = l.rows*(cell+1) + 1
+
+	l.width = l.boardWidth
+	for _, f := range frames {
+		l.width = max(l.width, textWidth(f.Title, animTextScale))
+		for _, line := range f.Legend {
+			l.width = max(l.width, animSwatch+6+textWidth(line, animTextScale))
+		}
+	}
+	l.width += 2 * animPad
+
+	l.boardX = animPad
+	l.boardY = animPad + l.lineHeight
+	l.legendY = l.boardY + l.boardHeight + animLineGap*2
+	l.height = l.legendY + len(frames[0].Legend)*l.lineHeight + animPad - animLineGap
+	return l
+}
+
+// cellOrigin is the top left pixel of a cell's fill. Hex rows shift half
+// a cell right of the row above.
+func (l animLayout) cellOrigin(pos Position) (int, int) {
+	x := l.boardX + 1 + pos.Col*(l.cell+1)
+	if l.hex {
+		x += pos.Row * (l.cell + 1) / 2
+	}
+	return x, l.boardY + 1 + pos.Row*(l.cell+1)
+}
+
+// labelScale is the font pixel size of the player IDs on the heads
+func (l animLayout) labelScale() int {
+	return max(1, (l.cell-4)/(glyphHeight+3))
+}
+
+// drawFrame renders one frame as an image
+func drawFrame(f animFrame, l animLayout) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, l.width, l.height))
+	fill := func(x, y, w, h int, hex string) {
+		draw.Draw(img, image.Rect(x, y, x+w, y+h), &image.Uniform{parseHexColor(hex)}, image.Point{}, draw.Src)
+	}
+	text := parseHexColor(animText)
+
+	fill(0, 0, l.width, l.height, animBackground)
+	drawText(img, animPad, animPad, f.Title, animTextScale, text)
+
+	if !l.hex {
+		fill(l.boardX, l.boardY, l.boardWidth, l.boardHeight, animGridLine)
+	}
+	for row, cells := range f.Cells {
+		for col, hex := range cells {
+			if hex == "" {
+				hex = animBackground
+			}
+			x, y := l.cellOrigin(Position{row, col})
+			if l.hex {
+				// The shifted rows leave no rectangle to draw the grid on
+				fill(x-1, y-1, l.cell+2, l.cell+2, animGridLine)
+			}
+			fill(x, y, l.cell, l.cell, hex)
+		}
+	}
+
+	scale := l.labelScale()
+	for i, head := range f.Heads {
+		x, y := l.cellOrigin(head)
+		id := PlayerIDs[i]
+		drawText(img, x+(l.cell-textWidth(id, scale))/2, y+(l.cell-glyphHeight*scale)/2, id, scale, color.White)
+	}
+
+	for i, line := range f.Legend {
+		y := l.legendY + i*l.lineHeight
+		fill(animPad, y, animSwatch, animSwatch, PlayerColors[i%len(PlayerColors)])
+		drawText(img, animPad+animSwatch+6, y, line, animTextScale, text)
+	}
+	return img
+}
+
+// writeGIF writes the frames as a looping animated GIF
+func writeGIF(path string, frames []animFrame, cell int, delay, hold time.Duration) error {
+	l := newAnimLayout(frames, cell)
+
+	// Every color the frames use, so no pixel has to be approximated
+	palette := color.Palette{parseHexColor(animBackground), parseHexColor(animGridLine), parseHexColor(animText), color.White}
+	seen := make(map[string]bool)
+	for _, f := range frames {
+		for _, cells := range f.Cells {
+			for _, hex := range cells {
+				if hex != "" && !seen[hex] {
+					seen[hex] = true
+					palette = append(palette, parseHexColor(hex))
+				}
+			}
+		}
+	}
+	for i := range frames[0].Heads {
+		palette = append(palette, parseHexColor(PlayerColors[i%len(PlayerColors)]))
+	}
+
+	anim := &gif.GIF{}
+	for i, f := range frames {
+		img := drawFrame(f, l)
+		paletted := image.NewPaletted(img.Bounds(), palette)
+		draw.Draw(paletted, img.Bounds(), img, image.Point{}, draw.Src)
+		anim.Image = append(anim.Image, paletted)
+		wait := delay
+		if i == len(frames)-1 {
+			wait = hold
+		}
+		anim.Delay = append(anim.Delay, int(wait/(10*time.Millisecond)))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return gif.EncodeAll(file, anim)
+}
+
+// writePNGFrames writes one numbered PNG per frame, named after path, e.g.
+// game-000.png, game-001.png, ...
+func writePNGFrames(path string, frames []animFrame, cell int) ([]string, error) {
+	l := newAnimLayout(frames, cell)
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	digits := max(3, len(strconv.Itoa(len(frames)-1)))
+
+	var written []string
+	for i, f := range frames {
+		name := fmt.Sprintf("%s-%0*d.png", base, digits, i)
+		file, err := os.Create(name)
+		if err != nil {
+			return written, err
+		}
+		err = png.Encode(file, drawFrame(f, l))
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return written, err
+		}
+		written = append(written, name)
+	}
+	return written, nil
+}
+
+// writeSVG writes the frames as one SVG animated with SMIL: every cell,
+// head label and line of text changes at the moment its frame starts, and
+// the animation repeats after the final position has been held
+func writeSVG(path string, frames []animFrame, cell int, delay, hold time.Duration) error {
+	l := newAnimLayout(frames, cell)
+	total := delay*time.Duration(len(frames)-1) + hold
+	keyTimes := make([]string, len(frames))
+	for i := range frames {
+		keyTimes[i] = strconv.FormatFloat(float64(delay*time.Duration(i))/float64(total), 'f', 4, 64)
+	}
+	dur := strconv.FormatFloat(total.Seconds(), 'f', 2, 64) + "s"
+
+	// animate changes an attribute at the frames where its value changes
+	animate := func(attr string, values []string) string {
+		var vals, times []string
+		for i, v := range values {
+			if i == 0 || v != values[i-1] {
+				vals = append(vals, v)
+				times = append(times, keyTimes[i])
+			}
+		}
+		if len(vals) == 1 {
+			return ""
+		}
+		times[0] = "0"
+		return fmt.Sprintf(`<animate attributeName="%s" values="%s" keyTimes="%s" dur="%s" calcMode="discrete" repeatCount="indefinite"/>`,
+			attr, strings.Join(vals, ";"), strings.Join(times, ";"), dur)
+	}
+
+	// timedText shows each run of identical text only while it is current
+	fontSize := glyphHeight * animTextScale * 10 / 7
+	var buf strings.Builder
+	timedText := func(x, y int, lines []string) {
+		for start := 0; start < len(lines); {
+			end := start + 1
+			for end < len(lines) && lines[end] == lines[start] {
+				end++
+			}
+			visibility := make([]string, len(lines))
+			for i := range visibility {
+				visibility[i] = "hidden"
+				if i >= start && i < end {
+					visibility[i] = "visible"
+				}
+			}
+			fmt.Fprintf(&buf, `<text x="%d" y="%d" font-size="%d">%s%s</text>`+"\n",
+				x, y+glyphHeight*animTextScale, fontSize, html.EscapeString(lines[start]), animate("visibility", visibility))
+			start = end
+		}
+	}
+
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace" fill="%s">`+"\n",
+		l.width, l.height, l.width, l.height, animText)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"/>`+"\n", l.width, l.height, animBackground)
+
+	titles := make([]string, len(frames))
+	for i, f := range frames {
+		titles[i] = f.Title
+	}
+	timedText(animPad, animPad, titles)
+
+	if !l.hex {
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", l.boardX, l.boardY, l.boardWidth, l.boardHeight, animGridLine)
+	}
+	for row := 0; row < l.rows; row++ {
+		for col := 0; col < l.cols; col++ {
+			fills := make([]string, len(frames))
+			for i, f := range frames {
+				fills[i] = f.Cells[row][col]
+				if fills[i] == "" {
+					fills[i] = animBackground
+				}
+			}
+			x, y := l.cellOrigin(Position{row, col})
+			if l.hex {
+				fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", x-1, y-1, l.cell+2, l.cell+2, animGridLine)
+			}
+			fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s">%s</rect>`+"\n",
+				x, y, l.cell, l.cell, fills[0], animate("fill", fills))
+		}
+	}
+
+	labelSize := glyphHeight * l.labelScale() * 10 / 7
+	for p := range frames[0].Heads {
+		xs := make([]string, len(frames))
+		ys := make([]string, len(frames))
+		for i, f := range frames {
+			x, y := l.cellOrigin(f.Heads[p])
+			xs[i] = strconv.Itoa(x + l.cell/2)
+			ys[i] = strconv.Itoa(y + l.cell/2)
+		}
+		fmt.Fprintf(&buf, `<text x="%s" y="%s" font-size="%d" font-weight="bold" fill="#ffffff" text-anchor="middle" dominant-baseline="central">%s%s%s</text>`+"\n",
+			xs[0], ys[0], labelSize, PlayerIDs[p], animate("x", xs), animate("y", ys))
+	}
+
+	for p := range frames[0].Legend {
+		y := l.legendY + p*l.lineHeight
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+			animPad, y, animSwatch, animSwatch, PlayerColors[p%len(PlayerColors)])
+		lines := make([]string, len(frames))
+		for i, f := range frames {
+			lines[i] = f.Legend[p]
+		}
+		timedText(animPad+animSwatch+6, y, lines)
+	}
+	buf.WriteString("</svg>\n")
+
+	return os.WriteFile(path, []byte(buf.String()), 0o644)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Arena is a board layout loaded from a text file. Each line is a row of
+// the board, one character per cell:
+//
+//	.  empty (a space works too)
+//	#  wall
+//	x  blocked cell
+//	1-9, A  fixed spawn point of that player
+//
+// Lines starting with ";" are comments. Players without a spawn point
+// start at a random free cell.
+type Arena struct {
+	Name    string
+	Version string // Hash of the file, so any edit yields a new version
+	Width   int
+	Height  int
+	Walls   []Position
+	Blocked []Position
+	Spawns  map[string]Position // Fixed start position by player ID
+}
+
+// LoadArena reads an arena file
+func LoadArena(path string) (*Arena, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	arena := &Arena{
+		Name:    filepath.Base(path),
+		Version: hex.EncodeToString(sum[:6]),
+		Spawns:  make(map[string]Position),
+	}
+
+	var rows []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, ";") {
+			continue
+		}
+		rows = append(rows, line)
+	}
+	// Blank lines only count between rows
+	for len(rows) > 0 && strings.TrimSpace(rows[len(rows)-1]) == "" {
+		rows = rows[:len(rows)-1]
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%s: no rows", path)
+	}
+
+	arena.Height = len(rows)
+	arena.Width = len([]rune(rows[0]))
+	for row, line := range rows {
+		cells := []rune(line)
+		if len(cells) != arena.Width {
+			return nil, fmt.Errorf("%s: row %d has %d cells, want %d", path, row, len(cells), arena.Width)
+		}
+		for col, code := range cells {
+			pos := Position{row, col}
+			switch {
+			case code == '.' || code == ' ':
+			case code == '#':
+				arena.Walls = append(arena.Walls, pos)
+			case code == 'x' || code == 'X':
+				arena.Blocked = append(arena.Blocked, pos)
+			case playerIndex(string(code)) >= 0:
+				id := string(code)
+				if _, ok := arena.Spawns[id]; ok {
+					return nil, fmt.Errorf("%s: player %s has two spawn points", path, id)
+				}
+				arena.Spawns[id] = pos
+			default:
+				return nil, fmt.Errorf("%s: unknown cell %q at (%d,%d)", path, code, row, col)
+			}
+		}
+	}
+	return arena, nil
+}
+
+// Label identifies the arena in results, e.g. "maze.txt@3f2a9c01d4e5"
+func (a *Arena) Label() string {
+	return a.Name + "@" + a.Version
+}
+
+// obstacleGrid marks the cells of a setup that cannot be entered
+func obstacleGrid(setup *GameSetup) [][]bool {
+	grid := make([][]bool, setup.Height)
+	for row := range grid {
+		grid[row] = make([]bool, setup.Width)
+	}
+	for _, pos := range append(append([]Position(nil), setup.Walls...), setup.Blocked...) {
+		grid[pos.Row][pos.Col] = true
+	}
+	return grid
+}
+
+// neighboursConnected reports whether the free neighbours of a cell that
+// has just been filled can still reach each other. A cell can be filled
+// without splitting the free area exactly when this holds.
+func neighboursConnected(grid [][]bool, pos Position) bool {
+	inside := func(p Position) bool {
+		return p.Row >= 0 && p.Row < len(grid) && p.Col >= 0 && p.Col < len(grid[0])
+	}
+	var neighbours []Position
+	for _, dir := range []Direction{Up, Down, Left, Right} {
+		if next := stepPosition(pos, dir); inside(next) && !grid[next.Row][next.Col] {
+			neighbours = append(neighbours, next)
+		}
+	}
+	if len(neighbours) <= 1 {
+		return true
+	}
+
+	seen := map[Position]bool{neighbours[0]: true}
+	queue := []Position{neighbours[0]}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		for _, dir := range []Direction{Up, Down, Left, Right} {
+			next := stepPosition(cell, dir)
+			if inside(next) && !grid[next.Row][next.Col] && !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	for _, n := range neighbours[1:] {
+		if !seen[n] {
+			return false
+		}
+	}
+	return true
+}
+
+// addObstacles walls off a share of the free cells at random. A cell only
+// becomes a wall if the free cells around it stay connected, so players
+// can still reach every free cell they could reach before, and reserved
+// cells (fixed spawn points) stay free.
+func addObstacles(setup *GameSetup, density float64, reserved map[Position]bool, rng *rand.Rand) {
+	grid := obstacleGrid(setup)
+	var free []Position
+	for row := range grid {
+		for col := range grid[row] {
+			if !grid[row][col] {
+				free = append(free, Position{row, col})
+			}
+		}
+	}
+
+	target := int(math.Round(density * float64(len(free))))
+	rng.Shuffle(len(free), func(i, j int) { free[i], free[j] = free[j], free[i] })
+	for _, pos := range free {
+		if target == 0 {
+			break
+		}
+		if reserved[pos] {
+			continue
+		}
+		grid[pos.Row][pos.Col] = true
+		if !neighboursConnected(grid, pos) {
+			grid[pos.Row][pos.Col] = false
+			continue
+		}
+		setup.Walls = append(setup.Walls, pos)
+		target--
+	}
+}
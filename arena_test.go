@@ -0,0 +1,129 @@
+package main
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// freeCellsConnected reports whether every free cell of a setup can be
+// reached from every other
+func freeCellsConnected(setup *GameSetup) bool {
+	grid := obstacleGrid(setup)
+	var free []Position
+	for row := range grid {
+		for col := range grid[row] {
+			if !grid[row][col] {
+				free = append(free, Position{row, col})
+			}
+		}
+	}
+	if len(free) == 0 {
+		return true
+	}
+
+	seen := map[Position]bool{free[0]: true}
+	queue := []Position{free[0]}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		for _, dir := range SquareDirections {
+			next := stepPosition(cell, dir)
+			if next.Row >= 0 && next.Row < setup.Height && next.Col >= 0 && next.Col < setup.Width &&
+				!grid[next.Row][next.Col] && !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return len(seen) == len(free)
+}
+
+func TestAddObstaclesKeepsBoardConnected(t *testing.T) {
+	for seed := int64(0); seed < 200; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		setup := &GameSetup{Width: 3 + rng.Intn(12), Height: 3 + rng.Intn(12)}
+		reserved := map[Position]bool{{0, 0}: true, {setup.Height - 1, setup.Width - 1}: true}
+
+		addObstacles(setup, 0.5, reserved, rng)
+
+		if !freeCellsConnected(setup) {
+			t.Fatalf("seed %d: %dx%d board split by obstacles %v", seed, setup.Width, setup.Height, setup.Walls)
+		}
+		for _, wall := range setup.Walls {
+			if reserved[wall] {
+				t.Fatalf("seed %d: reserved cell %v became a wall", seed, wall)
+			}
+		}
+		if len(setup.Walls) == 0 {
+			t.Fatalf("seed %d: no obstacles on a %dx%d board", seed, setup.Width, setup.Height)
+		}
+	}
+}
+
+func TestAddObstaclesDensity(t *testing.T) {
+	// An open board can be filled to the target without splitting it
+	setup := &GameSetup{Width: 20, Height: 20}
+	addObstacles(setup, 0.1, nil, rand.New(rand.NewSource(1)))
+	if len(setup.Walls) != 40 {
+		t.Errorf("%d obstacles at density 0.1 on 400 cells, want 40", len(setup.Walls))
+	}
+}
+
+func TestNeighboursConnected(t *testing.T) {
+	// A wall across the middle column splits the board when completed
+	grid := [][]bool{
+		{false, true, false},
+		{false, false, false},
+		{false, true, false},
+	}
+	if !neighboursConnected(grid, Position{0, 1}) {
+		t.Error("filled top cell: free neighbours reported split")
+	}
+	grid[1][1] = true
+	if neighboursConnected(grid, Position{1, 1}) {
+		t.Error("completed wall: free neighbours reported connected")
+	}
+}
+
+func TestLoadArena(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rooms.txt")
+	source := "; two rooms\n1..#...\n...#.x.\n.......\n...#..2\n"
+	if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	arena, err := LoadArena(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if arena.Width != 7 || arena.Height != 4 {
+		t.Errorf("size %dx%d, want 7x4", arena.Width, arena.Height)
+	}
+	if want := []Position{{0, 3}, {1, 3}, {3, 3}}; !reflect.DeepEqual(arena.Walls, want) {
+		t.Errorf("walls %v, want %v", arena.Walls, want)
+	}
+	if want := []Position{{1, 5}}; !reflect.DeepEqual(arena.Blocked, want) {
+		t.Errorf("blocked %v, want %v", arena.Blocked, want)
+	}
+	if want := map[string]Position{"1": {0, 0}, "2": {3, 6}}; !reflect.DeepEqual(arena.Spawns, want) {
+		t.Errorf("spawns %v, want %v", arena.Spawns, want)
+	}
+
+	for name, bad := range map[string]string{
+		"ragged":      "...\n..\n",
+		"two spawns":  "1.1\n...\n",
+		"unknown":     "..?\n...\n",
+		"only a note": "; empty\n",
+	} {
+		path := filepath.Join(t.TempDir(), "bad.txt")
+		if err := os.WriteFile(path, []byte(bad), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadArena(path); err == nil {
+			t.Errorf("%s arena loaded, want an error", name)
+		}
+	}
+}
@@ -0,0 +1,525 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// OutputMode selects how a model's answer is constrained
+type OutputMode string
+
+const (
+	OutputText    OutputMode = "text"    // Free text, parsed with regular expressions
+	OutputJSON    OutputMode = "json"    // JSON object whose move is restricted to the legal directions
+	OutputGrammar OutputMode = "grammar" // GBNF grammar allowing only the legal directions
+)
+
+// OutputModes lists all output modes, the default first
+var OutputModes = []OutputMode{OutputText, OutputJSON, OutputGrammar}
+
+// LLMRequest is one generation request, independent of the backend
+type LLMRequest struct {
+	Model       string
+	Prompt      string
+	Temperature float64
+	Output      OutputMode
+	ValidMoves  []Direction // Directions the constrained output may contain
+	Reasoning   bool        // Leave room for reasoning before the move
+}
+
+// Backend sends prompts to an LLM server
+type Backend interface {
+	Name() string
+	URL() string
+	Supports(mode OutputMode) bool
+	Generate(req *LLMRequest) (string, error)
+}
+
+// backendDefaults maps backend names to their default endpoint
+var backendDefaults = map[string]string{
+	"ollama":   "http://localhost:11434/api/generate",
+	"openai":   "http://localhost:11434/v1/chat/completions",
+	"llamacpp": "http://localhost:8080/completion",
+}
+
+// NewBackend creates the named backend, using its default URL when url is
+// empty
+func NewBackend(name, url string) (Backend, error) {
+	defaultURL, ok := backendDefaults[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown API %q (known: ollama, openai, llamacpp)", name)
+	}
+	if url == "" {
+		url = defaultURL
+	}
+
+	switch name {
+	case "openai":
+		return &openAIBackend{url: url, apiKey: os.Getenv("OPENAI_API_KEY")}, nil
+	case "llamacpp":
+		return &llamaCppBackend{url: url}, nil
+	}
+	return &ollamaBackend{url: url}, nil
+}
+
+// ParseOutputMode validates an output mode name
+func ParseOutputMode(name string) (OutputMode, error) {
+	for _, mode := range OutputModes {
+		if OutputMode(strings.ToLower(name)) == mode {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output mode %q (known: text, json, grammar)", name)
+}
+
+// moveSchema returns a JSON schema allowing only the legal directions. With
+// reasoning, a reasoning property comes first so it is generated before the
+// move.
+func moveSchema(validMoves []Direction, reasoning bool) json.RawMessage {
+	enum, _ := json.Marshal(validMoves)
+	move := fmt.Sprintf(`"move":{"type":"string","enum":%s}`, enum)
+	if reasoning {
+		return json.RawMessage(`{"type":"object","properties":{"reasoning":{"type":"string"},` + move +
+			`},"required":["reasoning","move"],"additionalProperties":false}`)
+	}
+	return json.RawMessage(`{"type":"object","properties":{` + move +
+		`},"required":["move"],"additionalProperties":false}`)
+}
+
+// moveGrammar returns a GBNF grammar allowing only the legal directions.
+// With reasoning, any number of lines may precede the final MOVE line.
+func moveGrammar(validMoves []Direction, reasoning bool) string {
+	alternatives := make([]string, len(validMoves))
+	for i, dir := range validMoves {
+		alternatives[i] = fmt.Sprintf("%q", string(dir))
+	}
+	move := "move ::= " + strings.Join(alternatives, " | ") + "\n"
+	if reasoning {
+		return "root ::= line* \"MOVE: \" move\nline ::= [^\\n]* \"\\n\"\n" + move
+	}
+	return "root ::= move\n" + move
+}
+
+// ParseJSONMove reads the move, and any reasoning, from a JSON answer. The
+// contents of <think> blocks before the JSON count as reasoning.
+func ParseJSONMove(response string, validMoves []Direction) (Direction, string, error) {
+	var thoughts []string
+	for _, match := range thinkBlockPattern.FindAllStringSubmatch(response, -1) {
+		if thought := strings.TrimSpace(match[1]); thought != "" {
+			thoughts = append(thoughts, thought)
+		}
+	}
+
+	var answer struct {
+		Reasoning string `json:"reasoning"`
+		Move      string `json:"move"`
+	}
+	if err := json.Unmarshal([]byte(thinkBlockPattern.ReplaceAllString(response, "")), &answer); err != nil {
+		return "", "", err
+	}
+	if reasoning := strings.TrimSpace(answer.Reasoning); reasoning != "" {
+		thoughts = append(thoughts, reasoning)
+	}
+
+	dir := Direction(strings.ToLower(strings.TrimSpace(answer.Move)))
+	for _, validDir := range validMoves {
+		if dir == validDir {
+			return dir, strings.Join(thoughts, "\n\n"), nil
+		}
+	}
+	return "", strings.Join(thoughts, "\n\n"), fmt.Errorf("direction '%s' is not valid", dir)
+}
+
+// postJSON posts a JSON request and decodes the JSON response
+func postJSON(url string, headers map[string]string, request, response any) error {
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			renderer.Warning(fmt.Sprintf("Error closing response body: %v", err))
+		}
+	}(resp.Body)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, response)
+}
+
+// withThinking prepends separately returned thinking as a <think> block, so
+// the reasoning parser sees it like inline thinking
+func withThinking(thinking, response string) string {
+	if thinking != "" {
+		return strings.TrimSpace("<think>" + thinking + "</think>\n" + response)
+	}
+	return strings.TrimSpace(response)
+}
+
+// OllamaRequest represents the request to the Ollama generate API
+type OllamaRequest struct {
+	Model   string          `json:"model"`
+	Prompt  string          `json:"prompt"`
+	Stream  bool            `json:"stream"`
+	Format  json.RawMessage `json:"format,omitempty"`  // JSON schema for structured output
+	Options map[string]any  `json:"options,omitempty"` // Sampling options such as the temperature
+}
+
+// OllamaResponse represents the response from the Ollama generate API
+type OllamaResponse struct {
+	Response string `json:"response"`
+	Thinking string `json:"thinking"` // Set by Ollama for models that think separately
+}
+
+// ollamaBackend talks to Ollama's /api/generate
+type ollamaBackend struct {
+	url string
+}
+
+func (b *ollamaBackend) Name() string { return "ollama" }
+func (b *ollamaBackend) URL() string  { return b.url }
+
+func (b *ollamaBackend) Supports(mode OutputMode) bool {
+	return mode == OutputText || mode == OutputJSON
+}
+
+func (b *ollamaBackend) Generate(req *LLMRequest) (string, error) {
+	reqBody := OllamaRequest{
+		Model:   req.Model,
+		Prompt:  req.Prompt,
+		Stream:  false,
+		Options: map[string]any{"temperature": req.Temperature},
+	}
+	if req.Output == OutputJSON {
+		reqBody.Format = moveSchema(req.ValidMoves, req.Reasoning)
+	}
+
+	var ollamaResp OllamaResponse
+	if err := postJSON(b.url, nil, reqBody, &ollamaResp); err != nil {
+		return "", err
+	}
+	return withThinking(ollamaResp.Thinking, ollamaResp.Response), nil
+}
+
+// openAIRequest is a chat completion request to an OpenAI-compatible API
+type openAIRequest struct {
+	Model          string          `json:"model"`
+	Messages       []openAIMessage `json:"messages"`
+	Temperature    float64         `json:"temperature"`
+	Tools          []any           `json:"tools,omitempty"`
+	ResponseFormat any             `json:"response_format,omitempty"`
+}
+
+// openAIMessage is a chat message as sent and received. Tool calls and the
+// ID of the call a tool message answers are only set in agent mode.
+type openAIMessage struct {
+	Role             string           `json:"role"`
+	Content          string           `json:"content"`
+	ReasoningContent string           `json:"reasoning_content,omitempty"` // Returned by some reasoning servers
+	ToolCalls        []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID       string           `json:"tool_call_id,omitempty"`
+}
+
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"` // JSON encoded as a string
+	} `json:"function"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// openAIBackend talks to an OpenAI-compatible /v1/chat/completions
+// endpoint, authenticating with OPENAI_API_KEY when it is set
+type openAIBackend struct {
+	url    string
+	apiKey string
+}
+
+func (b *openAIBackend) Name() string { return "openai" }
+func (b *openAIBackend) URL() string  { return b.url }
+
+func (b *openAIBackend) Supports(mode OutputMode) bool {
+	return mode == OutputText || mode == OutputJSON
+}
+
+func (b *openAIBackend) Generate(req *LLMRequest) (string, error) {
+	reqBody := openAIRequest{
+		Model:       req.Model,
+		Messages:    []openAIMessage{{Role: "user", Content: req.Prompt}},
+		Temperature: req.Temperature,
+	}
+	if req.Output == OutputJSON {
+		reqBody.ResponseFormat = openAIResponseFormat(req.ValidMoves, req.Reasoning)
+	}
+
+	headers := map[string]string{}
+	if b.apiKey != "" {
+		headers["Authorization"] = "Bearer " + b.apiKey
+	}
+
+	var resp openAIResponse
+	if err := postJSON(b.url, headers, reqBody, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("response contained no choices")
+	}
+	message := resp.Choices[0].Message
+	return withThinking(message.ReasoningContent, message.Content), nil
+}
+
+// openAIResponseFormat wraps the move schema as an OpenAI response format
+func openAIResponseFormat(validMoves []Direction, reasoning bool) any {
+	return map[string]any{
+		"type": "json_schema",
+		"json_schema": map[string]any{
+			"name":   "move",
+			"strict": true,
+			"schema": moveSchema(validMoves, reasoning),
+		},
+	}
+}
+
+// llamaCppRequest is a request to the llama.cpp server's /completion
+type llamaCppRequest struct {
+	Prompt      string          `json:"prompt"`
+	Temperature float64         `json:"temperature"`
+	Grammar     string          `json:"grammar,omitempty"`
+	JSONSchema  json.RawMessage `json:"json_schema,omitempty"`
+}
+
+type llamaCppResponse struct {
+	Content string `json:"content"`
+}
+
+// llamaCppBackend talks to the llama.cpp server, which serves a single model
+// and constrains output with either a JSON schema or a GBNF grammar
+type llamaCppBackend struct {
+	url string
+}
+
+func (b *llamaCppBackend) Name() string { return "llamacpp" }
+func (b *llamaCppBackend) URL() string  { return b.url }
+
+func (b *llamaCppBackend) Supports(mode OutputMode) bool {
+	return true
+}
+
+func (b *llamaCppBackend) Generate(req *LLMRequest) (string, error) {
+	reqBody := llamaCppRequest{
+		Prompt:      req.Prompt,
+		Temperature: req.Temperature,
+	}
+	switch req.Output {
+	case OutputJSON:
+		reqBody.JSONSchema = moveSchema(req.ValidMoves, req.Reasoning)
+	case OutputGrammar:
+		reqBody.Grammar = moveGrammar(req.ValidMoves, req.Reasoning)
+	}
+
+	var resp llamaCppResponse
+	if err := postJSON(b.url, nil, reqBody, &resp); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(resp.Content), nil
+}
+
+// ChatMessage is one message of a conversation with an LLM
+type ChatMessage struct {
+	Role       string // system, user, assistant or tool
+	Content    string
+	ToolCalls  []ToolCall // Calls requested by the assistant
+	ToolCallID string     // Call a tool message answers
+}
+
+// ToolCall is a function call requested by the model
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments json.RawMessage // JSON object
+}
+
+// ToolSpec describes a function the model may call
+type ToolSpec struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage // JSON schema of the arguments
+}
+
+// ChatRequest is one turn of a conversation, independent of the backend
+type ChatRequest struct {
+	Model       string
+	Temperature float64
+	Messages    []ChatMessage
+	Tools       []ToolSpec
+	Output      OutputMode  // Constrains the reply like LLMRequest.Output (text and json only)
+	ValidMoves  []Direction // Directions the constrained output may contain
+	Reasoning   bool        // Leave room for reasoning before the move
+}
+
+// ChatBackend is implemented by backends with a chat API, which supports
+// multi-turn conversations and function calling
+type ChatBackend interface {
+	Chat(req *ChatRequest) (*ChatMessage, error)
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"` // JSON object
+	} `json:"function"`
+}
+
+type ollamaChatMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Thinking  string           `json:"thinking,omitempty"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+}
+
+type ollamaChatRequest struct {
+	Model    string              `json:"model"`
+	Messages []ollamaChatMessage `json:"messages"`
+	Stream   bool                `json:"stream"`
+	Tools    []any               `json:"tools,omitempty"`
+	Format   json.RawMessage     `json:"format,omitempty"`
+	Options  map[string]any      `json:"options,omitempty"`
+}
+
+// functionTools converts tool specs to the OpenAI tools format, which
+// Ollama shares
+func functionTools(tools []ToolSpec) []any {
+	var specs []any
+	for _, tool := range tools {
+		specs = append(specs, map[string]any{
+			"type": "function",
+			"function": map[string]any{
+				"name":        tool.Name,
+				"description": tool.Description,
+				"parameters":  tool.Parameters,
+			},
+		})
+	}
+	return specs
+}
+
+// Chat sends a conversation to Ollama's /api/chat, next to /api/generate
+func (b *ollamaBackend) Chat(req *ChatRequest) (*ChatMessage, error) {
+	reqBody := ollamaChatRequest{
+		Model:   req.Model,
+		Stream:  false,
+		Tools:   functionTools(req.Tools),
+		Options: map[string]any{"temperature": req.Temperature},
+	}
+	if req.Output == OutputJSON {
+		reqBody.Format = moveSchema(req.ValidMoves, req.Reasoning)
+	}
+	for _, msg := range req.Messages {
+		wire := ollamaChatMessage{Role: msg.Role, Content: msg.Content}
+		for _, call := range msg.ToolCalls {
+			var wireCall ollamaToolCall
+			wireCall.Function.Name = call.Name
+			wireCall.Function.Arguments = call.Arguments
+			wire.ToolCalls = append(wire.ToolCalls, wireCall)
+		}
+		reqBody.Messages = append(reqBody.Messages, wire)
+	}
+
+	url := strings.TrimSuffix(b.url, "/generate") + "/chat"
+	var resp struct {
+		Message ollamaChatMessage `json:"message"`
+	}
+	if err := postJSON(url, nil, reqBody, &resp); err != nil {
+		return nil, err
+	}
+
+	reply := &ChatMessage{Role: "assistant", Content: withThinking(resp.Message.Thinking, resp.Message.Content)}
+	for i, call := range resp.Message.ToolCalls {
+		reply.ToolCalls = append(reply.ToolCalls, ToolCall{
+			ID:        fmt.Sprintf("call_%d", i),
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+	}
+	return reply, nil
+}
+
+// Chat sends a conversation to the chat completions endpoint
+func (b *openAIBackend) Chat(req *ChatRequest) (*ChatMessage, error) {
+	reqBody := openAIRequest{
+		Model:       req.Model,
+		Temperature: req.Temperature,
+	}
+	if len(req.Tools) > 0 {
+		reqBody.Tools = functionTools(req.Tools)
+	}
+	if req.Output == OutputJSON {
+		reqBody.ResponseFormat = openAIResponseFormat(req.ValidMoves, req.Reasoning)
+	}
+	for _, msg := range req.Messages {
+		wire := openAIMessage{Role: msg.Role, Content: msg.Content, ToolCallID: msg.ToolCallID}
+		for _, call := range msg.ToolCalls {
+			wireCall := openAIToolCall{ID: call.ID, Type: "function"}
+			wireCall.Function.Name = call.Name
+			wireCall.Function.Arguments = string(call.Arguments)
+			wire.ToolCalls = append(wire.ToolCalls, wireCall)
+		}
+		reqBody.Messages = append(reqBody.Messages, wire)
+	}
+
+	headers := map[string]string{}
+	if b.apiKey != "" {
+		headers["Authorization"] = "Bearer " + b.apiKey
+	}
+
+	var resp openAIResponse
+	if err := postJSON(b.url, headers, reqBody, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("response contained no choices")
+	}
+
+	message := resp.Choices[0].Message
+	reply := &ChatMessage{Role: "assistant", Content: withThinking(message.ReasoningContent, message.Content)}
+	for _, call := range message.ToolCalls {
+		arguments := json.RawMessage(call.Function.Arguments)
+		if !json.Valid(arguments) {
+			arguments = json.RawMessage("{}")
+		}
+		reply.ToolCalls = append(reply.ToolCalls, ToolCall{ID: call.ID, Name: call.Function.Name, Arguments: arguments})
+	}
+	return reply, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseJSONMove(t *testing.T) {
+	valid := []Direction{Up, Left, DownRight}
+	tests := []struct {
+		response  string
+		want      Direction
+		reasoning string
+	}{
+		{`{"move":"up"}`, Up, ""},
+		{`{"move":" Down-Right "}`, DownRight, ""},
+		{`{"reasoning":"Left has more room.","move":"left"}`, Left, "Left has more room."},
+		{"<think>Up is next to a trail.</think>\n{\"reasoning\":\" Left is open. \",\"move\":\"left\"}", Left,
+			"Up is next to a trail.\n\nLeft is open."},
+		{`{"move":"up","confidence":0.9}`, Up, ""},
+	}
+	for _, tt := range tests {
+		got, reasoning, err := ParseJSONMove(tt.response, valid)
+		if err != nil {
+			t.Errorf("ParseJSONMove(%q): %v", tt.response, err)
+			continue
+		}
+		if got != tt.want || reasoning != tt.reasoning {
+			t.Errorf("ParseJSONMove(%q) = %s, %q; want %s, %q", tt.response, got, reasoning, tt.want, tt.reasoning)
+		}
+	}
+
+	for _, response := range []string{`{"move":"right"}`, `{"move":""}`, `up`, `{"move":"up"`, `["up"]`} {
+		if dir, _, err := ParseJSONMove(response, valid); err == nil {
+			t.Errorf("ParseJSONMove(%q) = %s, want an error", response, dir)
+		}
+	}
+}
+
+func TestMoveSchema(t *testing.T) {
+	tests := []struct {
+		reasoning bool
+		want      string
+	}{
+		{false, `{"type":"object","properties":{"move":{"type":"string","enum":["up","down-left"]}},` +
+			`"required":["move"],"additionalProperties":false}`},
+		{true, `{"type":"object","properties":{"reasoning":{"type":"string"},"move":{"type":"string","enum":["up","down-left"]}},` +
+			`"required":["reasoning","move"],"additionalProperties":false}`},
+	}
+	for _, tt := range tests {
+		schema := moveSchema([]Direction{Up, DownLeft}, tt.reasoning)
+		if string(schema) != tt.want {
+			t.Errorf("moveSchema(reasoning %v) =\n%s\nwant\n%s", tt.reasoning, schema, tt.want)
+		}
+		if !json.Valid(schema) {
+			t.Errorf("moveSchema(reasoning %v) is not valid JSON", tt.reasoning)
+		}
+	}
+}
+
+func TestMoveGrammar(t *testing.T) {
+	tests := []struct {
+		reasoning bool
+		want      string
+	}{
+		{false, "root ::= move\nmove ::= \"up\" | \"down-left\"\n"},
+		{true, "root ::= line* \"MOVE: \" move\nline ::= [^\\n]* \"\\n\"\nmove ::= \"up\" | \"down-left\"\n"},
+	}
+	for _, tt := range tests {
+		if got := moveGrammar([]Direction{Up, DownLeft}, tt.reasoning); got != tt.want {
+			t.Errorf("moveGrammar(reasoning %v) =\n%s\nwant\n%s", tt.reasoning, got, tt.want)
+		}
+	}
+}
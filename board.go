@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// BoardEncoding selects how the board is drawn in the prompt
+type BoardEncoding string
+
+const (
+	EncodingGrid   BoardEncoding = "grid"   // Bordered grid with trail glyphs (the original drawing)
+	EncodingASCII  BoardEncoding = "ascii"  // Plain ASCII: heads by player ID, trails by letter
+	EncodingCoords BoardEncoding = "coords" // Coordinate lists of every player's occupied cells
+	EncodingJSON   BoardEncoding = "json"   // JSON matrix of rows using the ASCII cell codes
+	EncodingLocal  BoardEncoding = "local"  // Window around the player with relative offsets
+)
+
+// BoardEncodings lists all encodings, the default first
+var BoardEncodings = []BoardEncoding{EncodingGrid, EncodingASCII, EncodingCoords, EncodingJSON, EncodingLocal}
+
+// localWindowRadius is how many cells the local view shows in each direction
+const localWindowRadius = 3
+
+// ParseBoardEncoding validates an encoding name
+func ParseBoardEncoding(name string) (BoardEncoding, error) {
+	for _, encoding := range BoardEncodings {
+		if BoardEncoding(strings.ToLower(name)) == encoding {
+			return encoding, nil
+		}
+	}
+	return "", fmt.Errorf("unknown board encoding %q (known: %s)", name, boardEncodingNames())
+}
+
+func boardEncodingNames() string {
+	names := make([]string, len(BoardEncodings))
+	for i, encoding := range BoardEncodings {
+		names[i] = string(encoding)
+	}
+	return strings.Join(names, ", ")
+}
+
+// FormatBoard draws the board for a player in the given encoding
+func FormatBoard(game *GameState, player string, encoding BoardEncoding) string {
+	switch encoding {
+	case EncodingASCII:
+		return formatBoardASCII(game, player)
+	case EncodingCoords:
+		return formatBoardCoords(game, player)
+	case EncodingJSON:
+		return formatBoardJSON(game, player)
+	case EncodingLocal:
+		return formatBoardLocal(game, player)
+	}
+	return formatBoardForPrompt(game)
+}
+
+// cellCode returns the ASCII code of a cell: "." when empty, the player ID
+// for a head, a lowercase letter (a for Player 1, b for Player 2, ...) for
+// a trail and the cell itself for walls (#) and blocked cells (x)
+func cellCode(game *GameState, pos Position) string {
+	cell := game.Grid[pos.Row][pos.Col]
+	if cell == Empty {
+		return "."
+	}
+	for i, trail := range TrailChars {
+		if cell == trail {
+			return string(rune('a' + i))
+		}
+	}
+	return cell
+}
+
+// cellLegend explains the ASCII cell codes for the players in the game
+func cellLegend(game *GameState, player string) string {
+	parts := []string{". = empty"}
+	for i := 0; i < game.NumPlayers; i++ {
+		id := PlayerIDs[i]
+		name := "Player " + id
+		if id == player {
+			name += " (you)"
+		}
+		parts = append(parts, fmt.Sprintf("%s = %s, %c = trail of %s", id, name, 'a'+i, name))
+	}
+	if len(gridCells(game, Wall)) > 0 {
+		parts = append(parts, Wall+" = wall")
+	}
+	if len(gridCells(game, Blocked)) > 0 {
+		parts = append(parts, Blocked+" = blocked cell")
+	}
+	return "Legend: " + strings.Join(parts, "; ") + "\n"
+}
+
+// gridCells lists the cells holding the given value as "(row,col)"
+func gridCells(game *GameState, value string) []string {
+	var cells []string
+	for row := 0; row < game.Height; row++ {
+		for col := 0; col < game.Width; col++ {
+			if game.Grid[row][col] == value {
+				cells = append(cells, fmt.Sprintf("(%d,%d)", row, col))
+			}
+		}
+	}
+	return cells
+}
+
+func formatBoardASCII(game *GameState, player string) string {
+	var buf bytes.Buffer
+	buf.WriteString(cellLegend(game, player))
+	buf.WriteString("Row 0 is the top and column 0 the left edge.\n")
+	if game.Geometry == GeometryHex {
+		buf.WriteString(hexNote(game))
+		buf.WriteString(formatHexBoard(game, func(pos Position) string { return cellCode(game, pos) }))
+		return buf.String()
+	}
+	for row := 0; row < game.Height; row++ {
+		for col := 0; col < game.Width; col++ {
+			buf.WriteString(cellCode(game, Position{row, col}))
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+func formatBoardCoords(game *GameState, player string) string {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("Board: %d columns by %d rows, rows 0-%d from top to bottom, columns 0-%d from left to right.\n",
+		game.Width, game.Height, game.Height-1, game.Width-1))
+	buf.WriteString("Occupied cells as (row,col); all other cells are empty.\n")
+	buf.WriteString(hexNote(game))
+	if walls := gridCells(game, Wall); len(walls) > 0 {
+		buf.WriteString("Walls: " + strings.Join(walls, ", ") + "\n")
+	}
+	if blocked := gridCells(game, Blocked); len(blocked) > 0 {
+		buf.WriteString("Blocked cells: " + strings.Join(blocked, ", ") + "\n")
+	}
+
+	for i := 0; i < game.NumPlayers; i++ {
+		id := PlayerIDs[i]
+		trail := gridCells(game, TrailChars[i])
+
+		buf.WriteString("Player " + id)
+		if id == player {
+			buf.WriteString(" (you)")
+		}
+		head := game.PlayerPos[id]
+		buf.WriteString(fmt.Sprintf(": head (%d,%d)", head.Row, head.Col))
+		if !game.ActivePlayers[id] {
+			buf.WriteString(" [eliminated]")
+		}
+		if len(trail) == 0 {
+			buf.WriteString("; trail none\n")
+		} else {
+			buf.WriteString("; trail " + strings.Join(trail, ", ") + "\n")
+		}
+	}
+	return buf.String()
+}
+
+func formatBoardJSON(game *GameState, player string) string {
+	var buf bytes.Buffer
+	buf.WriteString(cellLegend(game, player))
+	buf.WriteString("JSON array of rows, row 0 (top) first; each row lists columns from left to right.\n")
+	buf.WriteString(hexNote(game))
+	buf.WriteString("[\n")
+	for row := 0; row < game.Height; row++ {
+		cells := make([]string, game.Width)
+		for col := range cells {
+			cells[col] = cellCode(game, Position{row, col})
+		}
+		encoded, _ := json.Marshal(cells)
+		buf.WriteString("  ")
+		buf.Write(encoded)
+		if row < game.Height-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("]\n")
+	return buf.String()
+}
+
+func formatBoardLocal(game *GameState, player string) string {
+	var buf bytes.Buffer
+	center := game.PlayerPos[player]
+	size := 2*localWindowRadius + 1
+
+	buf.WriteString(cellLegend(game, player))
+	// Hex rows are shifted by half a cell, which needs an even cell width
+	cellWidth, offsets := 3, "up is row -1, down row +1, left column -1, right column +1."
+	if game.Geometry == GeometryHex {
+		cellWidth, offsets = 4, "up-left is row -1, up-right row -1 column +1, left column -1, right column +1, "+
+			"down-left row +1 column -1, down-right row +1. Each row is drawn half a cell right of the row above; "+
+			"the column offsets line up with the top row."
+	}
+	buf.WriteString(fmt.Sprintf("%dx%d view centred on you; # = wall or outside the board. Offsets are relative to you: %s\n",
+		size, size, offsets))
+	if game.Topology == TopologyTorus {
+		buf.WriteString("The board wraps around, so the view continues across its edges.\n")
+	}
+
+	buf.WriteString("    ")
+	for dc := -localWindowRadius; dc <= localWindowRadius; dc++ {
+		buf.WriteString(fmt.Sprintf("%*s", cellWidth, fmt.Sprintf("%+d", dc)))
+	}
+	buf.WriteString("\n")
+
+	for dr := -localWindowRadius; dr <= localWindowRadius; dr++ {
+		buf.WriteString(fmt.Sprintf("%3s ", fmt.Sprintf("%+d", dr)))
+		if game.Geometry == GeometryHex {
+			buf.WriteString(strings.Repeat(" ", (dr+localWindowRadius)*cellWidth/2))
+		}
+		for dc := -localWindowRadius; dc <= localWindowRadius; dc++ {
+			pos := game.Wrap(Position{center.Row + dr, center.Col + dc})
+			code := "#"
+			if game.InBounds(pos) {
+				code = cellCode(game, pos)
+			}
+			buf.WriteString(fmt.Sprintf("%*s", cellWidth, code))
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
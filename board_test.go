@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestFormatBoard(t *testing.T) {
+	// Both players made one move on a 4x3 board with a wall
+	setup := &GameSetup{
+		Width: 4, Height: 3,
+		Walls:          []Position{{0, 3}},
+		StartPositions: []Position{{0, 0}, {2, 3}},
+		Players:        []*PlayerConfig{{Model: "a"}, {Model: "b"}},
+	}
+	game := InitGame(setup)
+	MakeMove(game, "1", Right)
+	MakeMove(game, "2", Left)
+
+	legend := "Legend: . = empty; 1 = Player 1 (you), a = trail of Player 1 (you); 2 = Player 2, b = trail of Player 2; # = wall\n"
+	tests := []struct {
+		encoding BoardEncoding
+		want     string
+	}{
+		{EncodingGrid, "     0  1  2  3 \n" +
+			" 0 | ░ | 1 |   | # |\n" +
+			" 1 |   |   |   |   |\n" +
+			" 2 |   |   | 2 | ▒ |\n"},
+		{EncodingASCII, legend + `Row 0 is the top and column 0 the left edge.
+a1.#
+....
+..2b
+`},
+		{EncodingCoords, `Board: 4 columns by 3 rows, rows 0-2 from top to bottom, columns 0-3 from left to right.
+Occupied cells as (row,col); all other cells are empty.
+Walls: (0,3)
+Player 1 (you): head (0,1); trail (0,0)
+Player 2: head (2,2); trail (2,3)
+`},
+		{EncodingJSON, legend + `JSON array of rows, row 0 (top) first; each row lists columns from left to right.
+[
+  ["a","1",".","#"],
+  [".",".",".","."],
+  [".",".","2","b"]
+]
+`},
+		{EncodingLocal, legend + `7x7 view centred on you; # = wall or outside the board. Offsets are relative to you: up is row -1, down row +1, left column -1, right column +1.
+     -3 -2 -1 +0 +1 +2 +3
+ -3   #  #  #  #  #  #  #
+ -2   #  #  #  #  #  #  #
+ -1   #  #  #  #  #  #  #
+ +0   #  #  a  1  .  #  #
+ +1   #  #  .  .  .  .  #
+ +2   #  #  .  .  2  b  #
+ +3   #  #  #  #  #  #  #
+`},
+	}
+	for _, tt := range tests {
+		if got := FormatBoard(game, "1", tt.encoding); got != tt.want {
+			t.Errorf("FormatBoard(%s) =\n%s\nwant\n%s", tt.encoding, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ExampleSelection selects which examples of a library a prompt shows
+type ExampleSelection string
+
+const (
+	SelectFixed   ExampleSelection = "fixed"   // The first examples of the file, every turn
+	SelectRandom  ExampleSelection = "random"  // A fresh random draw every turn
+	SelectSimilar ExampleSelection = "similar" // The examples closest to the current position
+)
+
+// ExampleSelections lists all selection strategies, the default first
+var ExampleSelections = []ExampleSelection{SelectFixed, SelectRandom, SelectSimilar}
+
+// ParseExampleSelection validates a selection strategy name
+func ParseExampleSelection(name string) (ExampleSelection, error) {
+	for _, selection := range ExampleSelections {
+		if ExampleSelection(strings.ToLower(name)) == selection {
+			return selection, nil
+		}
+	}
+	return "", fmt.Errorf("unknown example selection %q (known: fixed, random, similar)", name)
+}
+
+// Example is a curated position with its correct move. The board is given
+// in the ASCII encoding: "." for empty cells, the player ID for a head, a
+// lowercase letter (a for Player 1, b for Player 2, ...) for a trail, "#"
+// for a wall and "x" for a blocked cell.
+type Example struct {
+	Name     string    `json:"name,omitempty"`
+	Player   string    `json:"player"`
+	Board    []string  `json:"board"`
+	Topology Topology  `json:"topology,omitempty"` // Empty for a bounded board
+	Geometry Geometry  `json:"geometry,omitempty"` // Empty for a square board
+	Move     Direction `json:"move"`
+	Note     string    `json:"note,omitempty"` // Why the move is correct
+
+	game     *GameState
+	features []float64
+}
+
+// ExampleLibrary is a file of examples. Its version is a hash of the file,
+// so any edit to the examples yields a new version.
+type ExampleLibrary struct {
+	Name     string
+	Version  string
+	Examples []*Example
+}
+
+// LoadExamples reads an example library: JSON objects, one per line as
+// written by the examples command, or hand-formatted one after another
+func LoadExamples(path string) (*ExampleLibrary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	library := &ExampleLibrary{
+		Name:    filepath.Base(path),
+		Version: hex.EncodeToString(sum[:6]),
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	for {
+		var example Example
+		if err := decoder.Decode(&example); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: example %d: %w", path, len(library.Examples)+1, err)
+		}
+		if err := example.parse(); err != nil {
+			return nil, fmt.Errorf("%s: example %d: %w", path, len(library.Examples)+1, err)
+		}
+		library.Examples = append(library.Examples, &example)
+	}
+	if len(library.Examples) == 0 {
+		return nil, fmt.Errorf("%s: no examples", path)
+	}
+	return library, nil
+}
+
+// parse rebuilds the example's position and checks that its move is legal
+func (e *Example) parse() error {
+	if len(e.Board) == 0 || len(e.Board[0]) == 0 {
+		return fmt.Errorf("empty board")
+	}
+	if e.Topology != "" {
+		topology, err := ParseTopology(string(e.Topology))
+		if err != nil {
+			return err
+		}
+		e.Topology = topology
+	}
+	geometry := GeometrySquare
+	if e.Geometry != "" {
+		var err error
+		if geometry, err = ParseGeometry(string(e.Geometry)); err != nil {
+			return err
+		}
+		e.Geometry = geometry
+	}
+	game := &GameState{
+		Width:         len(e.Board[0]),
+		Height:        len(e.Board),
+		Topology:      e.Topology,
+		Geometry:      geometry,
+		Grid:          make([][]string, len(e.Board)),
+		PlayerPos:     make(map[string]Position),
+		ActivePlayers: make(map[string]bool),
+		Visited:       make(map[Position]bool),
+	}
+
+	for row, line := range e.Board {
+		if len(line) != game.Width {
+			return fmt.Errorf("row %d has %d cells, want %d", row, len(line), game.Width)
+		}
+		game.Grid[row] = make([]string, game.Width)
+		for col, code := range line {
+			pos := Position{row, col}
+			var index int
+			switch {
+			case code == '.':
+				game.Grid[row][col] = Empty
+				continue
+			case code == '#' || code == 'x':
+				game.Grid[row][col] = string(code)
+				game.Visited[pos] = true
+				continue
+			case code >= 'a' && code <= 'j':
+				index = int(code - 'a')
+				game.Grid[row][col] = TrailChars[index]
+			default:
+				id := string(code)
+				if index = playerIndex(id); index < 0 {
+					return fmt.Errorf("unknown cell %q at (%d,%d)", code, row, col)
+				}
+				if _, ok := game.PlayerPos[id]; ok {
+					return fmt.Errorf("player %s has two heads", id)
+				}
+				game.Grid[row][col] = id
+				game.PlayerPos[id] = pos
+				game.ActivePlayers[id] = true
+			}
+			game.Visited[pos] = true
+			if index+1 > game.NumPlayers {
+				game.NumPlayers = index + 1
+			}
+		}
+	}
+
+	if _, ok := game.PlayerPos[e.Player]; !ok {
+		return fmt.Errorf("player %q has no head on the board", e.Player)
+	}
+	e.Move = Direction(strings.ToLower(string(e.Move)))
+	validMoves := GetValidMoves(game, e.Player)
+	legal := false
+	for _, dir := range validMoves {
+		legal = legal || dir == e.Move
+	}
+	if !legal {
+		return fmt.Errorf("move %q is not legal (valid: %s)", e.Move, formatValidMoves(validMoves))
+	}
+
+	game.CenterNorm = maxCenterDistance(game)
+	e.game = game
+	e.features = boardFeatures(game, e.Player)
+	return nil
+}
+
+// boardFeatures describes a player's situation for similarity search, each
+// feature scaled to about 0-1: free space, mobility, distance to the wall,
+// distance to the nearest opponent and the share of the free space the
+// player can still reach
+func boardFeatures(game *GameState, player string) []float64 {
+	pos := game.PlayerPos[player]
+	cells := float64(game.Width * game.Height)
+	free := cells - float64(len(game.Visited))
+
+	wall := min(pos.Row, pos.Col, game.Height-1-pos.Row, game.Width-1-pos.Col)
+	opponent := 1.0
+	for id, active := range game.ActivePlayers {
+		if id == player || !active {
+			continue
+		}
+		other := game.PlayerPos[id]
+		distance := float64(game.Distance(pos, other))
+		opponent = math.Min(opponent, distance/float64(max(1, game.Width+game.Height-2)))
+	}
+
+	reachable := 0.0
+	if free > 0 {
+		// The flood fill counts the player's own head
+		reachable = float64(countReachableTerritory(game, pos)-1) / free
+	}
+
+	return []float64{
+		free / cells,
+		float64(len(GetValidMoves(game, player))) / float64(len(game.Directions())),
+		float64(wall) / math.Max(1, float64(min(game.Width, game.Height)/2)),
+		opponent,
+		reachable,
+	}
+}
+
+// Select picks up to count examples for a player's current position.
+// Examples of another geometry than the game's are never shown, since
+// their moves mean something else there. A game without a geometry, e.g.
+// one stored before geometries existed, is square.
+func (l *ExampleLibrary) Select(game *GameState, player string, count int, selection ExampleSelection) []*Example {
+	hex := game.Geometry == GeometryHex
+	var examples []*Example
+	for _, example := range l.Examples {
+		if (example.game.Geometry == GeometryHex) == hex {
+			examples = append(examples, example)
+		}
+	}
+	count = min(count, len(examples))
+
+	switch selection {
+	case SelectRandom:
+		selected := make([]*Example, count)
+		for i, index := range rand.Perm(len(examples))[:count] {
+			selected[i] = examples[index]
+		}
+		return selected
+
+	case SelectSimilar:
+		features := boardFeatures(game, player)
+		distance := make(map[*Example]float64, len(examples))
+		for _, example := range examples {
+			sum := 0.0
+			for i, value := range features {
+				sum += (value - example.features[i]) * (value - example.features[i])
+			}
+			distance[example] = sum
+		}
+		ranked := append([]*Example(nil), examples...)
+		sort.SliceStable(ranked, func(i, j int) bool {
+			return distance[ranked[i]] < distance[ranked[j]]
+		})
+		return ranked[:count]
+	}
+
+	return examples[:count]
+}
+
+// RunExamplesCommand builds an example library from stored games: every
+// recorded position where one legal move is clearly worse than the engine's
+// best becomes an example, labelled with the best move
+func RunExamplesCommand(args []string) error {
+	fs := flag.NewFlagSet("examples", flag.ExitOnError)
+	path := fs.String("db", "snakes.db", "SQLite database written with -db")
+	run := fs.String("run", "", "Only include games from this run ID")
+	out := fs.String("out", "examples.jsonl", "Example library to write")
+	limit := fs.Int("n", 50, "Maximum number of examples, drawn at random (0 for all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(*path); err != nil {
+		return fmt.Errorf("database %s: %w", *path, err)
+	}
+	store, err := OpenStore(*path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	games, err := store.LoadGames(*run)
+	if err != nil {
+		return err
+	}
+
+	var examples []*Example
+	for _, stored := range games {
+		game := InitGame(stored.Record.Setup)
+		for i, move := range stored.Record.Moves {
+			if example := exampleFromPosition(game, move.Player); example != nil {
+				example.Name = fmt.Sprintf("run %s game %d move %d", stored.RunID, stored.Record.Number, i+1)
+				examples = append(examples, example)
+			}
+			MakeMove(game, move.Player, move.Direction)
+		}
+	}
+
+	found := len(examples)
+	if *limit > 0 && len(examples) > *limit {
+		rand.Shuffle(len(examples), func(i, j int) { examples[i], examples[j] = examples[j], examples[i] })
+		examples = examples[:*limit]
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	for _, example := range examples {
+		if err := encoder.Encode(example); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Wrote %d of %d instructive positions from %d games to %s\n", len(examples), found, len(games), *out)
+	return nil
+}
+
+// exampleFromPosition turns a position into an example when the choice
+// matters: some legal move walks into a dead end or gives up space the
+// best move keeps
+func exampleFromPosition(game *GameState, player string) *Example {
+	evaluations := RankMoves(game, player, GetValidMoves(game, player))
+	if len(evaluations) < 2 {
+		return nil
+	}
+	best, worst := evaluations[0], evaluations[len(evaluations)-1]
+	if best.ImmediateMoves == 0 {
+		return nil
+	}
+
+	var note string
+	switch {
+	case worst.ImmediateMoves == 0:
+		note = fmt.Sprintf("%s keeps %d cells reachable, %s is a dead end",
+			best.Direction, best.ReachableTerritory, worst.Direction)
+	case worst.ReachableTerritory < best.ReachableTerritory:
+		note = fmt.Sprintf("%s keeps %d cells reachable, %s only %d",
+			best.Direction, best.ReachableTerritory, worst.Direction, worst.ReachableTerritory)
+	default:
+		return nil
+	}
+
+	board := make([]string, game.Height)
+	for row := range board {
+		var line strings.Builder
+		for col := 0; col < game.Width; col++ {
+			line.WriteString(cellCode(game, Position{row, col}))
+		}
+		board[row] = line.String()
+	}
+	example := &Example{Player: player, Board: board, Move: best.Direction, Note: note}
+	if game.Topology == TopologyTorus {
+		example.Topology = TopologyTorus
+	}
+	if game.Geometry == GeometryHex {
+		example.Geometry = GeometryHex
+	}
+	return example
+}
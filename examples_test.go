@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testExamples = `{"name":"open","player":"1","board":[".....",".....","..1..",".....","....2"],"move":"up"}
+{"name":"corner","player":"1","board":["1....","aa...",".....",".....","....2"],"move":"right"}
+{"name":"pocket","player":"1","board":["aaaa.","aaa1.","aaaa.",".....","2...."],"move":"right"}
+{"name":"hex","player":"1","board":["..1..",".....","....2"],"geometry":"hex","move":"down-left"}
+`
+
+func TestExampleSelect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "examples.jsonl")
+	if err := os.WriteFile(path, []byte(testExamples), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	library, err := LoadExamples(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Player 1 has just moved up into the top left corner
+	game := InitGame(&GameSetup{
+		Width: 5, Height: 5,
+		StartPositions: []Position{{1, 0}, {4, 4}},
+		Players:        []*PlayerConfig{{Model: "a"}, {Model: "b"}},
+	})
+	MakeMove(game, "1", Up)
+
+	names := func(examples []*Example) []string {
+		var names []string
+		for _, example := range examples {
+			names = append(names, example.Name)
+		}
+		return names
+	}
+	equal := func(a, b []string) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	tests := []struct {
+		selection ExampleSelection
+		count     int
+		want      []string
+	}{
+		{SelectFixed, 2, []string{"open", "corner"}},
+		{SelectFixed, 10, []string{"open", "corner", "pocket"}},
+		{SelectFixed, 0, nil},
+		{SelectSimilar, 1, []string{"corner"}},
+		{SelectSimilar, 3, []string{"corner", "pocket", "open"}}, // Like the corner, the pocket leaves one move
+	}
+	for _, tt := range tests {
+		if got := names(library.Select(game, "1", tt.count, tt.selection)); !equal(got, tt.want) {
+			t.Errorf("Select(%d, %s) = %v, want %v", tt.count, tt.selection, got, tt.want)
+		}
+	}
+
+	// Random draws are distinct and, over many turns, cover every example
+	// of the game's geometry but no other
+	seen := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		drawn := names(library.Select(game, "1", 2, SelectRandom))
+		if len(drawn) != 2 || drawn[0] == drawn[1] {
+			t.Fatalf("random draw %v, want two distinct examples", drawn)
+		}
+		for _, name := range drawn {
+			seen[name]++
+		}
+	}
+	if len(seen) != 3 || seen["hex"] > 0 {
+		t.Errorf("random draws showed %v, want every square example", seen)
+	}
+
+	// A hex game only sees the hex example
+	hexGame := InitGame(&GameSetup{
+		Width: 5, Height: 5, Geometry: GeometryHex,
+		StartPositions: []Position{{0, 0}, {4, 4}},
+		Players:        []*PlayerConfig{{Model: "a"}, {Model: "b"}},
+	})
+	for _, selection := range ExampleSelections {
+		if got := names(library.Select(hexGame, "1", 3, selection)); !equal(got, []string{"hex"}) {
+			t.Errorf("Select(3, %s) on a hex board = %v, want [hex]", selection, got)
+		}
+	}
+}
+
+func TestLoadExamplesRejectsIllegalMoves(t *testing.T) {
+	for _, example := range []string{
+		`{"player":"1","board":["1a...","a...."],"move":"right"}`,
+		`{"player":"1","board":["..1..",".....","....2"],"geometry":"hex","move":"down"}`,
+		`{"player":"2","board":["..1.."],"move":"left"}`,
+		`{"player":"1","board":["..1..","...."],"move":"left"}`,
+		`{"player":"1","board":["..1.z"],"move":"left"}`,
+	} {
+		path := filepath.Join(t.TempDir(), "examples.jsonl")
+		if err := os.WriteFile(path, []byte(example), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadExamples(path); err == nil {
+			t.Errorf("LoadExamples accepted %s", example)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ResultSink receives every finished game
+type ResultSink interface {
+	WriteGame(runID string, record *GameRecord) error
+	Close() error
+}
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// GameRow is the per-game export format
+type GameRow struct {
+	RunID       string   `json:"run_id"`
+	Game        int      `json:"game"`
+	Seed        int64    `json:"seed"`
+	Width       int      `json:"width"`
+	Height      int      `json:"height"`
+	Topology    Topology `json:"topology"`
+	Geometry    Geometry `json:"geometry"`
+	Arena       string   `json:"arena"`
+	Players     int      `json:"players"`
+	Models      []string `json:"models"`
+	Prompt      string   `json:"prompt_version"`
+	Winner      string   `json:"winner"`
+	WinnerModel string   `json:"winner_model"`
+	Length      int      `json:"length"`
+	Retries     int      `json:"retries"`
+	Ambiguous   int      `json:"ambiguous"`
+	Error       string   `json:"error"`
+	Duration    float64  `json:"duration"`
+}
+
+// MoveRow is the per-move export format
+type MoveRow struct {
+	RunID      string    `json:"run_id"`
+	Game       int       `json:"game"`
+	Move       int       `json:"move"`
+	Player     string    `json:"player"`
+	Model      string    `json:"model"`
+	From       Position  `json:"from"`
+	To         Position  `json:"to"`
+	Direction  Direction `json:"direction"`
+	EngineRank int       `json:"engine_rank"`
+	NumOptions int       `json:"num_options"`
+	Safety     string    `json:"safety"`
+	BestSafety string    `json:"best_safety"`
+	Latency    float64   `json:"latency"`
+	Retries    int       `json:"retries"`
+	Ambiguous  int       `json:"ambiguous"`
+	Reasoning  string    `json:"reasoning"`
+	ToolCalls  int       `json:"tool_calls"`
+}
+
+var gameRowHeader = []string{
+	"run_id", "game", "seed", "width", "height", "topology", "geometry", "arena", "players", "models", "prompt_version",
+	"winner", "winner_model", "length", "retries", "ambiguous", "error", "duration",
+}
+
+var moveRowHeader = []string{
+	"run_id", "game", "move", "player", "model", "from_row", "from_col", "to_row", "to_col",
+	"direction", "engine_rank", "num_options", "safety", "best_safety", "latency", "retries",
+	"ambiguous", "reasoning", "tool_calls",
+}
+
+// gameRows converts a record into its per-game export row
+func gameRows(runID string, record *GameRecord) []any {
+	row := GameRow{
+		RunID:    runID,
+		Game:     record.Number,
+		Seed:     record.Setup.Seed,
+		Width:    record.Width,
+		Height:   record.Height,
+		Topology: record.Setup.Topology,
+		Geometry: record.Setup.Geometry,
+		Arena:    record.Setup.Arena,
+		Players:  len(record.Setup.Players),
+		Models:   record.Setup.Labels(),
+		Prompt:   record.PromptVersion,
+		Winner:   record.Winner,
+		Length:   len(record.Moves),
+		Error:    record.Error,
+		Duration: record.Duration,
+	}
+	if idx := playerIndex(record.Winner); idx >= 0 {
+		row.WinnerModel = record.Setup.Players[idx].Label()
+	}
+	for _, move := range record.Moves {
+		row.Retries += move.Retries
+		row.Ambiguous += move.Ambiguous
+	}
+	return []any{row}
+}
+
+// moveRows converts a record into one export row per move
+func moveRows(runID string, record *GameRecord) []any {
+	rows := make([]any, 0, len(record.Moves))
+	for i, move := range record.Moves {
+		rows = append(rows, MoveRow{
+			RunID:      runID,
+			Game:       record.Number,
+			Move:       i + 1,
+			Player:     move.Player,
+			Model:      record.Setup.Players[playerIndex(move.Player)].Label(),
+			From:       move.From,
+			To:         move.To,
+			Direction:  move.Direction,
+			EngineRank: move.EngineRank,
+			NumOptions: move.NumOptions,
+			Safety:     move.SafetyLevel,
+			BestSafety: move.BestSafety,
+			Latency:    move.Latency,
+			Retries:    move.Retries,
+			Ambiguous:  move.Ambiguous,
+			Reasoning:  move.Reasoning,
+			ToolCalls:  len(move.ToolCalls),
+		})
+	}
+	return rows
+}
+
+// csvValues flattens an export row into CSV cells
+func csvValues(row any) []string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+	switch r := row.(type) {
+	case GameRow:
+		return []string{
+			r.RunID, strconv.Itoa(r.Game), strconv.FormatInt(r.Seed, 10), strconv.Itoa(r.Width),
+			strconv.Itoa(r.Height), string(r.Topology), string(r.Geometry), r.Arena, strconv.Itoa(r.Players), strings.Join(r.Models, "|"), r.Prompt, r.Winner, r.WinnerModel,
+			strconv.Itoa(r.Length), strconv.Itoa(r.Retries), strconv.Itoa(r.Ambiguous), r.Error, f(r.Duration),
+		}
+	case MoveRow:
+		return []string{
+			r.RunID, strconv.Itoa(r.Game), strconv.Itoa(r.Move), r.Player, r.Model,
+			strconv.Itoa(r.From.Row), strconv.Itoa(r.From.Col), strconv.Itoa(r.To.Row), strconv.Itoa(r.To.Col),
+			string(r.Direction), strconv.Itoa(r.EngineRank), strconv.Itoa(r.NumOptions),
+			r.Safety, r.BestSafety, f(r.Latency), strconv.Itoa(r.Retries), strconv.Itoa(r.Ambiguous), r.Reasoning,
+			strconv.Itoa(r.ToolCalls),
+		}
+	}
+	return nil
+}
+
+// fileSink writes export rows to a CSV or JSON Lines file, chosen by the
+// file extension
+type fileSink struct {
+	file *os.File
+	csv  *csv.Writer
+	json *json.Encoder
+	rows func(runID string, record *GameRecord) []any
+}
+
+func newFileSink(path string, header []string, rows func(string, *GameRecord) []any) (*fileSink, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	// Rows are written one JSON object per line, which a .json file
+	// would promise to be a single JSON document
+	if ext != ".csv" && ext != ".jsonl" {
+		return nil, fmt.Errorf("%s: output must end in .csv or .jsonl", path)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := &fileSink{file: file, rows: rows}
+	if ext == ".csv" {
+		sink.csv = csv.NewWriter(file)
+		if err := sink.csv.Write(header); err != nil {
+			file.Close()
+			return nil, err
+		}
+		sink.csv.Flush()
+	} else {
+		sink.json = json.NewEncoder(file)
+	}
+	return sink, nil
+}
+
+// WriteGame appends the rows of a game and flushes them, so results survive
+// an interrupted run
+func (s *fileSink) WriteGame(runID string, record *GameRecord) error {
+	for _, row := range s.rows(runID, record) {
+		if s.csv != nil {
+			if err := s.csv.Write(csvValues(row)); err != nil {
+				return err
+			}
+		} else if err := s.json.Encode(row); err != nil {
+			return err
+		}
+	}
+	if s.csv != nil {
+		s.csv.Flush()
+		return s.csv.Error()
+	}
+	return nil
+}
+
+func (s *fileSink) Close() error {
+	return s.file.Close()
+}
+
+// OpenResultSinks opens every sink requested on the command line
+func OpenResultSinks() ([]ResultSink, error) {
+	var sinks []ResultSink
+
+	if dbPath != "" {
+		store, err := OpenStore(dbPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not open database %s: %w", dbPath, err)
+		}
+		sinks = append(sinks, store)
+	}
+
+	for _, path := range outPaths {
+		sink, err := newFileSink(path, gameRowHeader, gameRows)
+		if err != nil {
+			CloseResultSinks(sinks)
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	for _, path := range outMoves {
+		sink, err := newFileSink(path, moveRowHeader, moveRows)
+		if err != nil {
+			CloseResultSinks(sinks)
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	return sinks, nil
+}
+
+// CloseResultSinks closes all sinks, reporting but not stopping on errors
+func CloseResultSinks(sinks []ResultSink) {
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil {
+			fmt.Printf("Error closing results: %v\n", err)
+		}
+	}
+}
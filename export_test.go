@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+func TestExportRowsMatchHeaders(t *testing.T) {
+	record := testRecord(3)
+	tests := []struct {
+		name   string
+		header []string
+		rows   func(runID string, record *GameRecord) []any
+		want   int
+		cells  map[string]string
+	}{
+		{"game", gameRowHeader, gameRows, 1, map[string]string{
+			"run_id": "run", "game": "3", "seed": "42", "width": "5", "height": "4",
+			"topology": "torus", "geometry": "hex", "players": "2", "models": "llama3.2|mistral [agent]",
+			"winner": "1", "winner_model": "llama3.2", "length": "2", "retries": "1", "ambiguous": "1",
+			"duration": "1.500",
+		}},
+		{"move", moveRowHeader, moveRows, 2, map[string]string{
+			"run_id": "run", "game": "3", "move": "1", "player": "1", "model": "llama3.2",
+			"from_row": "1", "from_col": "1", "to_row": "1", "to_col": "2", "direction": "right",
+			"engine_rank": "1", "num_options": "6", "safety": "GOOD", "latency": "0.250",
+			"retries": "1", "ambiguous": "1", "tool_calls": "0",
+		}},
+	}
+	for _, tt := range tests {
+		rows := tt.rows("run", record)
+		if len(rows) != tt.want {
+			t.Fatalf("%s rows: got %d, want %d", tt.name, len(rows), tt.want)
+		}
+		for _, row := range rows {
+			if values := csvValues(row); len(values) != len(tt.header) {
+				t.Errorf("%s row has %d cells for %d columns", tt.name, len(values), len(tt.header))
+			}
+		}
+		values := csvValues(rows[0])
+		for i, column := range tt.header {
+			if want, ok := tt.cells[column]; ok && i < len(values) && values[i] != want {
+				t.Errorf("%s row %s = %q, want %q", tt.name, column, values[i], want)
+			}
+		}
+	}
+}
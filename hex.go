@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Geometry selects the shape of the board cells
+type Geometry string
+
+const (
+	GeometrySquare Geometry = "square" // Square cells with four neighbours
+	GeometryHex    Geometry = "hex"    // Hexagons with six neighbours
+)
+
+// Geometries lists all geometries, the default first
+var Geometries = []Geometry{GeometrySquare, GeometryHex}
+
+// ParseGeometry validates a geometry name
+func ParseGeometry(name string) (Geometry, error) {
+	for _, geometry := range Geometries {
+		if Geometry(strings.ToLower(name)) == geometry {
+			return geometry, nil
+		}
+	}
+	return "", fmt.Errorf("unknown geometry %q (known: square, hex)", name)
+}
+
+// A hex board is a rhombus of pointy-top hexagons in axial coordinates:
+// Position.Col is the axial q and Position.Row the axial r. Each row is
+// drawn half a cell further right than the row above, so the neighbours
+// of (row, col) are
+//
+//	up-left (row-1, col)      up-right (row-1, col+1)
+//	left    (row, col-1)      right    (row, col+1)
+//	down-left (row+1, col-1)  down-right (row+1, col)
+//
+// The square neighbours up and down are the hex neighbours up-left and
+// down-right, so a board connected on a square grid stays connected as a
+// hex board. Wrapping a torus works unchanged on the rhombus.
+
+// hexNeighbours describes the neighbours of a hex cell for prompts
+const hexNeighbours = "the neighbours of (row,col) are up-left (row-1,col), up-right (row-1,col+1), " +
+	"left (row,col-1), right (row,col+1), down-left (row+1,col-1) and down-right (row+1,col)"
+
+// hexNote explains the neighbours of a hex board for the board encodings,
+// and is empty on a square board
+func hexNote(game *GameState) string {
+	if game.Geometry != GeometryHex {
+		return ""
+	}
+	return "Hex board: " + hexNeighbours + ".\n"
+}
+
+// Directions returns the directions players can move in on this board
+func (g *GameState) Directions() []Direction {
+	if g.Geometry == GeometryHex {
+		return HexDirections
+	}
+	return SquareDirections
+}
+
+// Distance returns the number of moves between two cells on an empty board
+func (g *GameState) Distance(a, b Position) int {
+	best := -1
+	rows, cols := []int{b.Row - a.Row}, []int{b.Col - a.Col}
+	if g.Topology == TopologyTorus {
+		rows = append(rows, rows[0]-g.Height, rows[0]+g.Height)
+		cols = append(cols, cols[0]-g.Width, cols[0]+g.Width)
+	}
+	for _, dr := range rows {
+		for _, dc := range cols {
+			distance := abs(dr) + abs(dc)
+			if g.Geometry == GeometryHex {
+				distance = (abs(dr) + abs(dc) + abs(dr+dc)) / 2
+			}
+			if best < 0 || distance < best {
+				best = distance
+			}
+		}
+	}
+	return best
+}
+
+// hexCenter returns the drawn position of a hex cell, in cell widths
+func hexCenter(row, col float64) (x, y float64) {
+	return col + row/2, row * math.Sqrt(3) / 2
+}
+
+// hexGlyph draws the cells of a hex board with the glyphs of the grid
+// drawing, and empty cells as dots so the layout stays visible
+func hexGlyph(game *GameState) func(pos Position) string {
+	return func(pos Position) string {
+		if cell := game.Grid[pos.Row][pos.Col]; cell != Empty {
+			return cell
+		}
+		return "·"
+	}
+}
+
+// formatHexBoard draws a hex board with every row shifted half a cell
+// right of the row above. Column numbers are aligned with row 0.
+func formatHexBoard(game *GameState, code func(pos Position) string) string {
+	var buf bytes.Buffer
+	header := "    "
+	for col := 0; col < game.Width; col++ {
+		header += fmt.Sprintf("%-2d", col%10)
+	}
+	buf.WriteString(strings.TrimRight(header, " ") + "\n")
+
+	for row := 0; row < game.Height; row++ {
+		buf.WriteString(fmt.Sprintf("%2d  %s", row, strings.Repeat(" ", row)))
+		cells := make([]string, game.Width)
+		for col := range cells {
+			cells[col] = code(Position{row, col})
+		}
+		buf.WriteString(strings.Join(cells, " ") + "\n")
+	}
+	return buf.String()
+}
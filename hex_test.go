@@ -0,0 +1,108 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHexNeighbours(t *testing.T) {
+	// Axial neighbours of (2,2), as the prompt describes them
+	want := map[Direction]Position{
+		UpLeft:    {1, 2},
+		UpRight:   {1, 3},
+		Left:      {2, 1},
+		Right:     {2, 3},
+		DownLeft:  {3, 1},
+		DownRight: {3, 2},
+	}
+	for _, dir := range HexDirections {
+		if got := stepPosition(Position{2, 2}, dir); got != want[dir] {
+			t.Errorf("%s of (2,2) = %v, want %v", dir, got, want[dir])
+		}
+	}
+
+	// Every neighbour is one move away, and stepping back returns
+	game := &GameState{Width: 5, Height: 5, Geometry: GeometryHex}
+	opposite := map[Direction]Direction{
+		UpLeft: DownRight, UpRight: DownLeft, Left: Right,
+		Right: Left, DownLeft: UpRight, DownRight: UpLeft,
+	}
+	for _, dir := range HexDirections {
+		next := stepPosition(Position{2, 2}, dir)
+		if d := game.Distance(Position{2, 2}, next); d != 1 {
+			t.Errorf("distance to %s neighbour = %d, want 1", dir, d)
+		}
+		if back := stepPosition(next, opposite[dir]); back != (Position{2, 2}) {
+			t.Errorf("%s then %s ends at %v", dir, opposite[dir], back)
+		}
+	}
+}
+
+func TestDirections(t *testing.T) {
+	if got := (&GameState{}).Directions(); !reflect.DeepEqual(got, SquareDirections) {
+		t.Errorf("square directions = %v", got)
+	}
+	if got := (&GameState{Geometry: GeometryHex}).Directions(); len(got) != 6 {
+		t.Errorf("hex directions = %v, want six", got)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		geometry Geometry
+		topology Topology
+		a, b     Position
+		want     int
+	}{
+		{GeometrySquare, TopologyBounded, Position{0, 0}, Position{3, 4}, 7},
+		{GeometryHex, TopologyBounded, Position{0, 0}, Position{3, 4}, 7},
+		// Down-left steps cover a row and a column at once
+		{GeometryHex, TopologyBounded, Position{0, 4}, Position{4, 0}, 4},
+		{GeometryHex, TopologyBounded, Position{0, 0}, Position{4, 4}, 8},
+		{GeometryHex, TopologyBounded, Position{2, 2}, Position{2, 2}, 0},
+		// On a 6x6 torus the short way round crosses the edges
+		{GeometrySquare, TopologyTorus, Position{0, 0}, Position{5, 5}, 2},
+		{GeometryHex, TopologyTorus, Position{0, 0}, Position{5, 5}, 2},
+		{GeometryHex, TopologyTorus, Position{0, 0}, Position{0, 5}, 1},
+	}
+	for _, tt := range tests {
+		game := &GameState{Width: 6, Height: 6, Geometry: tt.geometry, Topology: tt.topology}
+		if got := game.Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s %s distance %v-%v = %d, want %d", tt.geometry, tt.topology, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestHexDistanceMatchesMoves(t *testing.T) {
+	// On an empty board the distance is the fewest moves, found by search
+	game := &GameState{Width: 6, Height: 5, Geometry: GeometryHex}
+	start := Position{2, 3}
+	moves := map[Position]int{start: 0}
+	queue := []Position{start}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		for _, dir := range HexDirections {
+			next := stepPosition(cell, dir)
+			if _, seen := moves[next]; seen || next.Row < 0 || next.Row >= game.Height || next.Col < 0 || next.Col >= game.Width {
+				continue
+			}
+			moves[next] = moves[cell] + 1
+			queue = append(queue, next)
+		}
+	}
+	for cell, want := range moves {
+		if got := game.Distance(start, cell); got != want {
+			t.Errorf("distance %v-%v = %d, want %d", start, cell, got, want)
+		}
+	}
+}
+
+func TestParseGeometry(t *testing.T) {
+	if g, err := ParseGeometry("HEX"); err != nil || g != GeometryHex {
+		t.Errorf("ParseGeometry(HEX) = %q, %v", g, err)
+	}
+	if _, err := ParseGeometry("triangle"); err == nil {
+		t.Error("ParseGeometry(triangle) succeeded, want an error")
+	}
+}
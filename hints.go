@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SafetyLevels lists the ratings of determineSafetyLevel from best to worst
+var SafetyLevels = []string{"EXCELLENT", "GOOD", "MODERATE", "RISKY", "DANGEROUS", "DEATH TRAP"}
+
+// HintStats measures how closely a model follows the move analysis in its
+// prompt. A model that blindly copies the ⭐ move looks smart without
+// playing independently, so these numbers belong next to its win rate.
+type HintStats struct {
+	Moves          int            // All moves
+	Unforced       int            // Moves with more than one option
+	FollowedTop    int            // Unforced moves that matched the top-ranked move
+	AvoidableTraps int            // DEATH TRAP moves chosen while a safer move existed
+	Safety         map[string]int // Chosen moves per safety level
+}
+
+// NewHintStats creates empty hint-following statistics
+func NewHintStats() *HintStats {
+	return &HintStats{Safety: make(map[string]int)}
+}
+
+// Add counts one move
+func (h *HintStats) Add(move Move) {
+	h.Moves++
+	if move.SafetyLevel != "" {
+		h.Safety[move.SafetyLevel]++
+	}
+	if move.NumOptions > 1 {
+		h.Unforced++
+		if move.EngineRank == 1 {
+			h.FollowedTop++
+		}
+	}
+	if move.SafetyLevel == "DEATH TRAP" && move.BestSafety != "" && move.BestSafety != "DEATH TRAP" {
+		h.AvoidableTraps++
+	}
+}
+
+// FollowRate returns the share of unforced moves that matched the top hint
+func (h *HintStats) FollowRate() float64 {
+	if h.Unforced == 0 {
+		return 0
+	}
+	return float64(h.FollowedTop) / float64(h.Unforced) * 100
+}
+
+// SafetySummary renders the distribution of chosen safety levels
+func (h *HintStats) SafetySummary() string {
+	parts := make([]string, 0, len(SafetyLevels))
+	for _, level := range SafetyLevels {
+		if count := h.Safety[level]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%s %.0f%%", level, float64(count)/float64(h.Moves)*100))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DisplayHintStats shows hint-following statistics for every model
+func DisplayHintStats(stats *GameStats) {
+	if len(stats.Hints) == 0 {
+		return
+	}
+
+	fmt.Println("Hint following:")
+	for _, model := range stats.Models {
+		h := stats.Hints[model]
+		if h == nil || h.Moves == 0 {
+			continue
+		}
+		fmt.Printf("  %s: top move %d/%d (%.1f%%), avoidable death traps %d\n",
+			model, h.FollowedTop, h.Unforced, h.FollowRate(), h.AvoidableTraps)
+		fmt.Printf("    chosen safety: %s\n", h.SafetySummary())
+	}
+}
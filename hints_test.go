@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestHintStats(t *testing.T) {
+	h := NewHintStats()
+	moves := []Move{
+		{NumOptions: 1, EngineRank: 1, SafetyLevel: "RISKY", BestSafety: "RISKY"},           // Forced
+		{NumOptions: 3, EngineRank: 1, SafetyLevel: "GOOD", BestSafety: "GOOD"},             // Followed
+		{NumOptions: 3, EngineRank: 1, SafetyLevel: "GOOD", BestSafety: "GOOD"},             // Followed
+		{NumOptions: 2, EngineRank: 2, SafetyLevel: "DEATH TRAP", BestSafety: "MODERATE"},   // Avoidable trap
+		{NumOptions: 2, EngineRank: 2, SafetyLevel: "DEATH TRAP", BestSafety: "DEATH TRAP"}, // No way out
+		{NumOptions: 1, EngineRank: 1, SafetyLevel: "DEATH TRAP", BestSafety: "DEATH TRAP"}, // Forced into a trap
+	}
+	for _, move := range moves {
+		h.Add(move)
+	}
+
+	if h.Moves != 6 || h.Unforced != 4 || h.FollowedTop != 2 {
+		t.Errorf("counted %d moves, %d unforced, %d followed; want 6, 4, 2", h.Moves, h.Unforced, h.FollowedTop)
+	}
+	if rate := h.FollowRate(); rate != 50 {
+		t.Errorf("FollowRate() = %.1f, want 50", rate)
+	}
+	if h.AvoidableTraps != 1 {
+		t.Errorf("AvoidableTraps = %d, want 1", h.AvoidableTraps)
+	}
+	want := "GOOD 33%, RISKY 17%, DEATH TRAP 50%"
+	if got := h.SafetySummary(); got != want {
+		t.Errorf("SafetySummary() = %q, want %q", got, want)
+	}
+
+	// Without unforced moves there is no rate to report
+	if rate := NewHintStats().FollowRate(); rate != 0 {
+		t.Errorf("FollowRate() of no moves = %.1f, want 0", rate)
+	}
+
+	// Moves recorded before the engine ranking was stored have no best
+	// safety, so their traps cannot count as avoidable
+	old := NewHintStats()
+	old.Add(Move{NumOptions: 2, SafetyLevel: "DEATH TRAP"})
+	if old.AvoidableTraps != 0 {
+		t.Errorf("AvoidableTraps without a best safety = %d, want 0", old.AvoidableTraps)
+	}
+}
@@ -2,20 +2,22 @@ package main
 
 import (
 	"bytes"
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
 	"flag"
 	"fmt"
-	"io"
+	"math"
 	"math/rand"
-	"net/http"
-	"regexp"
+	"os"
 	"strings"
 	"time"
 )
 
 // Constants for cell states
 const (
-	Empty = " "
+	Empty   = " "
+	Wall    = "#" // Part of the arena layout
+	Blocked = "x" // Pre-blocked cell, e.g. from an arena file
 )
 
 // Player identifiers and trail characters for up to 10 players
@@ -32,6 +34,18 @@ const (
 	Down  Direction = "down"
 	Left  Direction = "left"
 	Right Direction = "right"
+
+	// Diagonal directions of a hex board
+	UpLeft    Direction = "up-left"
+	UpRight   Direction = "up-right"
+	DownLeft  Direction = "down-left"
+	DownRight Direction = "down-right"
+)
+
+// Directions of each board geometry, in the order they are listed to players
+var (
+	SquareDirections = []Direction{Up, Down, Left, Right}
+	HexDirections    = []Direction{UpLeft, UpRight, Left, Right, DownLeft, DownRight}
 )
 
 // Position represents a coordinate on the grid
@@ -42,34 +56,155 @@ type Position struct {
 
 // Move represents a single move in the game
 type Move struct {
-	Player    string
-	Direction Direction
-	From      Position
-	To        Position
+	Player      string
+	Direction   Direction
+	From        Position
+	To          Position
+	PromptHash  string    // Hash of the prompt the move was chosen from
+	Response    string    // Raw LLM response that was accepted
+	Reasoning   string    // Reasoning before the final answer (reasoning mode)
+	Latency     float64   // Seconds spent waiting for the accepted response
+	Retries     int       // Invalid responses before the accepted one
+	Ambiguous   int       // Retries caused by responses naming several directions
+	EngineRank  int       // Position of the chosen move in the engine's ranking (1 = best)
+	NumOptions  int       // Number of valid moves the player had
+	SafetyLevel string    // Engine safety rating of the chosen move
+	BestSafety  string    // Engine safety rating of the top-ranked move
+	ToolCalls   []ToolUse // Tools called before the move (agent mode)
+	Attempts    []Attempt // Every response to the prompt, the accepted one last
+}
+
+// LLMDecision describes how a player's move was obtained from the LLM
+type LLMDecision struct {
+	Direction  Direction
+	Response   string
+	Reasoning  string
+	PromptHash string
+	Latency    float64
+	Retries    int
+	Ambiguous  int
+	ToolCalls  []ToolUse
+	Attempts   []Attempt
 }
 
 // PlayerConfig holds configuration for each player
 type PlayerConfig struct {
-	ID          string
-	Model       string
-	Temperature float64
+	ID            string
+	Model         string
+	Temperature   float64
+	Sections      map[PromptSection]bool // Enabled prompt sections, nil for all
+	Prompt        *PromptTemplate        // Prompt template, nil for the default
+	Board         BoardEncoding          // Board drawing in the prompt, "" for the grid
+	Reasoning     bool                   // Let the model think before a final MOVE line
+	Output        OutputMode             // How the answer is constrained, "" for free text
+	Agent         bool                   // Inspect the board with tools instead of one prompt
+	Memory        MemoryMode             // Conversation memory across turns, "" for stateless
+	MemoryTurns   int                    // Turns kept verbatim in memory
+	Examples      *ExampleLibrary        // Worked examples shown in the prompt, nil for none
+	ExampleSelect ExampleSelection       // Which examples each turn shows
+	ExampleCount  int                    // Examples shown per turn
+	Variant       string                 // Non-default prompt options, "" for the default prompt
+}
+
+// Label names the player in statistics: the model, plus the prompt variant
+// when it differs from the default, so ablations are counted separately
+func (c *PlayerConfig) Label() string {
+	if c.Variant == "" {
+		return c.Model
+	}
+	return fmt.Sprintf("%s [%s]", c.Model, c.Variant)
+}
+
+// PromptVersion returns the version of the player's prompt template
+func (c *PlayerConfig) PromptVersion() string {
+	if c.Prompt == nil {
+		return defaultPrompt.Version
+	}
+	return c.Prompt.Version
 }
 
 // GameState holds the complete game state
 type GameState struct {
 	Grid          [][]string
-	Size          int
+	Width         int // Columns
+	Height        int // Rows
+	Topology      Topology
+	Geometry      Geometry
 	NumPlayers    int
-	PlayerPos     map[string]Position    // Map of player ID to position
+	PlayerPos     map[string]Position      // Map of player ID to position
 	PlayerConfigs map[string]*PlayerConfig // Map of player ID to configuration
-	ActivePlayers map[string]bool        // Track which players are still in the game
+	ActivePlayers map[string]bool          // Track which players are still in the game
+	Moves         []Move
+	Visited       map[Position]bool  // Track all visited positions
+	EliminatedAt  map[string]int     // Map of player ID to the move number of elimination
+	Memories      map[string]*Memory // Conversations of players with memory
+	CenterNorm    float64            // Normalizes the center term of move scores, see maxCenterDistance
+}
+
+// GameSetup describes the starting conditions of a game: the start
+// position and player configuration of every seat. Seat i is played by
+// PlayerIDs[i] and moves i-th in each round.
+type GameSetup struct {
+	Seed           int64 // Seed the start positions and obstacles were drawn from
+	Width          int
+	Height         int
+	Topology       Topology
+	Geometry       Geometry
+	Arena          string     // Arena label and obstacle density, "" for an open board
+	Walls          []Position // Arena walls and generated obstacles
+	Blocked        []Position // Pre-blocked cells of the arena
+	StartPositions []Position
+	Players        []*PlayerConfig
+}
+
+// Labels returns the statistics label of every seat
+func (s *GameSetup) Labels() []string {
+	labels := make([]string, len(s.Players))
+	for i, player := range s.Players {
+		labels[i] = player.Label()
+	}
+	return labels
+}
+
+// PromptVersion returns the distinct prompt template versions of the
+// seats, comma-separated in the order they first appear. Which seat used
+// which version is stored with each player (see PlayerConfig.PromptVersion).
+func (s *GameSetup) PromptVersion() string {
+	var versions []string
+	seen := make(map[string]bool)
+	for _, player := range s.Players {
+		if version := player.PromptVersion(); !seen[version] {
+			seen[version] = true
+			versions = append(versions, version)
+		}
+	}
+	return strings.Join(versions, ",")
+}
+
+// GameRecord is the complete record of a finished game
+type GameRecord struct {
+	Number        int
+	StartedAt     time.Time
+	Duration      float64 // Seconds
+	Setup         *GameSetup
+	Width         int
+	Height        int
+	PromptVersion string // Distinct prompt template versions, see GameSetup.PromptVersion
+	Winner        string // Player ID of the winner, "" for a draw, "error" if aborted
+	Error         string
 	Moves         []Move
-	Visited       map[Position]bool // Track all visited positions
+	EliminatedAt  map[string]int // Map of player ID to the move number of elimination
 }
 
 // GameStats tracks statistics across multiple games
 type GameStats struct {
-	PlayerWins      map[string]int // Map of player ID to win count
+	PlayerWins      map[string]int            // Map of player ID to win count
+	ModelWins       map[string]int            // Map of model name to win count
+	ModelGames      map[string]int            // Map of model name to games played
+	Models          []string                  // Models in order of first appearance
+	HeadToHead      map[string]map[string]int // HeadToHead[winner][loser] counts decisive games
+	Hints           map[string]*HintStats     // Map of model name to hint-following statistics
+	Setups          []*SetupResult            // Per-setup results (mirror mode only)
 	Errors          int
 	TotalGames      int
 	ResponseTimes   []float64
@@ -78,51 +213,146 @@ type GameStats struct {
 	AvgResponseTime float64
 }
 
-// OllamaRequest represents the request to the LLM API
-type OllamaRequest struct {
-	Model       string  `json:"model"`
-	Prompt      string  `json:"prompt"`
-	Stream      bool    `json:"stream"`
-	Temperature float64 `json:"temperature"`
-}
-
-// OllamaResponse represents the response from the LLM API
-type OllamaResponse struct {
-	Response string `json:"response"`
-}
-
 var (
-	gridSize    int
-	numPlayers  int
-	llmURL      string
-	modelName   string
-	temperature float64
-	maxRetries  int
-	numGames    int
-	debugMode   bool
+	gridSize     int
+	boardWidth   int // -width, or -size if unset
+	boardHeight  int // -height, or -size if unset
+	arenaPath    string
+	arena        *Arena
+	obstacles    float64 // Share of free cells walled off at random
+	topologyName string
+	topology     Topology
+	geometryName string
+	geometry     Geometry
+	numPlayers   int
+	llmURL       string
+	apiName      string
+	outputName   string
+	backend      Backend
+	modelName    string
+	temperature  float64
+	maxRetries   int
+	numGames     int
+	debugMode    bool
+	reasoning    bool
+	agentMode    bool
+	maxToolCalls int
+	mirrorMode   bool
+	mirrorMax    int
+	sprtMode     bool
+	sprtP1       float64
+	sprtAlpha    float64
+	sprtBeta     float64
+	dbPath       string
+	outPaths     stringList
+	outMoves     stringList
+
+	// Prompt sections and template for all players, and per-player overrides
+	defaultSections   string
+	playerSections    [10]string
+	defaultPromptPath string
+	playerPrompts     [10]string
+	defaultBoard      string
+	playerBoards      [10]string
+	defaultMemory     string
+	playerMemories    [10]string
+	memoryTurns       int
+
+	// Output: verbosity of the text renderer, or the full-screen terminal view
+	verbosityName   string
+	verbosity       Verbosity
+	renderer        Renderer
+	tuiMode         bool
+	tuiDelay        time.Duration
+	tui             *TUI
+	serveAddr       string
+	defaultExamples string
+	playerExamples  [10]string
+	exampleSelect   string
+	exampleCount    int
 
 	// Per-player model overrides
-	player1Model string
-	player2Model string
-	player3Model string
-	player4Model string
-	player5Model string
-	player6Model string
-	player7Model string
-	player8Model string
-	player9Model string
+	player1Model  string
+	player2Model  string
+	player3Model  string
+	player4Model  string
+	player5Model  string
+	player6Model  string
+	player7Model  string
+	player8Model  string
+	player9Model  string
 	player10Model string
 )
 
 func init() {
 	flag.IntVar(&gridSize, "size", 12, "Grid size (NxN)")
+	flag.IntVar(&boardWidth, "width", 0, "Board width in columns (default -size)")
+	flag.IntVar(&boardHeight, "height", 0, "Board height in rows (default -size)")
+	flag.StringVar(&arenaPath, "arena", "", "Arena file with walls, blocked cells and spawn points (sets the board size)")
+	flag.Float64Var(&obstacles, "obstacles", 0, "Share of free cells to wall off at random, keeping the board connected (0-0.5)")
+	flag.StringVar(&topologyName, "topology", string(TopologyBounded), "Board edges: bounded, or torus to wrap around to the opposite edge")
+	flag.StringVar(&geometryName, "geometry", string(GeometrySquare), "Board cells: square, or hex for hexagons with six directions")
 	flag.IntVar(&numPlayers, "players", 2, "Number of players (2-10)")
-	flag.StringVar(&llmURL, "url", "http://localhost:11434/api/generate", "LLM API URL")
+	flag.StringVar(&llmURL, "url", "", "LLM API URL (default depends on -api)")
+	flag.StringVar(&apiName, "api", "ollama", "LLM API: ollama, openai (chat completions) or llamacpp (llama.cpp server)")
+	flag.StringVar(&outputName, "output", "text",
+		"Answer format: text, json (JSON schema) or grammar (GBNF); falls back to text where the API lacks support")
 	flag.StringVar(&modelName, "model", "llama3.2", "Default model name (used if no per-player model specified)")
 	flag.Float64Var(&temperature, "temp", 0.7, "Temperature for LLM")
 	flag.IntVar(&maxRetries, "retries", 3, "Max retries for invalid moves")
 	flag.IntVar(&numGames, "games", 1, "Number of games to play (0 for unlimited)")
 	flag.BoolVar(&debugMode, "debug", false, "Enable debug mode (show prompts)")
+	flag.BoolVar(&reasoning, "reasoning", false, "Let models reason freely and end with a 'MOVE: <direction>' line")
+	flag.BoolVar(&agentMode, "agent", false, "Let models inspect the board with tool calls (needs -api ollama or openai)")
+	flag.IntVar(&maxToolCalls, "max-tool-calls", 8, "Agent mode: maximum tool calls per turn")
+	flag.BoolVar(&mirrorMode, "mirror", false, "Replay each random setup with seats permuted (-games counts setups)")
+	flag.IntVar(&mirrorMax, "mirror-max", 4, "Mirror mode: largest player count that plays all N! seat orders; above it only the N rotations")
+	flag.BoolVar(&sprtMode, "sprt", false, "Stop early once one of two models is significantly stronger (SPRT)")
+	flag.Float64Var(&sprtP1, "sprt-p1", 0.6, "SPRT: win rate in decisive games that counts as significantly stronger")
+	flag.Float64Var(&sprtAlpha, "sprt-alpha", 0.05, "SPRT: false positive rate")
+	flag.Float64Var(&sprtBeta, "sprt-beta", 0.05, "SPRT: false negative rate")
+	flag.StringVar(&verbosityName, "verbosity", "board",
+		"Output per game: silent (final statistics only), summary (one line per game), moves (one line per move) or board")
+	flag.BoolVar(&tuiMode, "tui", false, "Show games in a full-screen terminal view with colors (keys: space, n, +, -, q)")
+	flag.DurationVar(&tuiDelay, "tui-delay", 300*time.Millisecond, "TUI: pause after each move")
+	flag.StringVar(&serveAddr, "serve", "", "Serve a live view of the games to browsers on this address, e.g. :8080")
+	flag.StringVar(&dbPath, "db", "", "SQLite database to store every game and move in (empty to disable)")
+	flag.Var(&outPaths, "out", "Write one row per game to a .csv or .jsonl file (repeatable)")
+	flag.Var(&outMoves, "out-moves", "Write one row per move to a .csv or .jsonl file (repeatable)")
+
+	flag.StringVar(&defaultSections, "sections", "all",
+		"Prompt sections: all, or a list of "+sectionNames(PromptSections)+"; prefix a section with - to drop it")
+	for i := range playerSections {
+		flag.StringVar(&playerSections[i], fmt.Sprintf("sections%d", i+1), "",
+			fmt.Sprintf("Prompt sections for Player %d (overrides -sections)", i+1))
+	}
+
+	flag.StringVar(&defaultPromptPath, "prompt", "", "Prompt template file (text/template, empty for the built-in prompt)")
+	for i := range playerPrompts {
+		flag.StringVar(&playerPrompts[i], fmt.Sprintf("prompt%d", i+1), "",
+			fmt.Sprintf("Prompt template file for Player %d (overrides -prompt)", i+1))
+	}
+
+	flag.StringVar(&defaultBoard, "board", string(EncodingGrid), "Board encoding in the prompt: "+boardEncodingNames())
+	for i := range playerBoards {
+		flag.StringVar(&playerBoards[i], fmt.Sprintf("board%d", i+1), "",
+			fmt.Sprintf("Board encoding for Player %d (overrides -board)", i+1))
+	}
+
+	flag.StringVar(&defaultMemory, "memory", string(MemoryOff),
+		"Conversation memory across turns: off, window (keep the last -memory-turns) or summary (also summarize older turns)")
+	for i := range playerMemories {
+		flag.StringVar(&playerMemories[i], fmt.Sprintf("memory%d", i+1), "",
+			fmt.Sprintf("Memory mode for Player %d (overrides -memory)", i+1))
+	}
+	flag.IntVar(&memoryTurns, "memory-turns", 5, "Turns kept verbatim in memory")
+	flag.StringVar(&defaultExamples, "examples", "", "Example library to show worked positions from (see the examples command)")
+	for i := range playerExamples {
+		flag.StringVar(&playerExamples[i], fmt.Sprintf("examples%d", i+1), "",
+			fmt.Sprintf("Example library for Player %d (overrides -examples)", i+1))
+	}
+	flag.StringVar(&exampleSelect, "example-select", string(SelectFixed), "Examples shown each turn: fixed, random or similar")
+	flag.IntVar(&exampleCount, "example-count", 3, "Examples shown per turn")
 
 	// Per-player model flags
 	flag.StringVar(&player1Model, "model1", "", "Model for Player 1 (overrides -model)")
@@ -137,6 +367,15 @@ func init() {
 	flag.StringVar(&player10Model, "model10", "", "Model for Player 10 (overrides -model)")
 }
 
+// subcommands maps subcommand names to their entry points
+var subcommands = map[string]func(args []string) error{
+	"stats":    RunStatsCommand,
+	"report":   RunReportCommand,
+	"analyze":  RunAnalyzeCommand,
+	"examples": RunExamplesCommand,
+	"animate":  RunAnimateCommand,
+}
+
 // getPlayerModel returns the model for a specific player index (0-based)
 func getPlayerModel(playerIndex int) string {
 	playerModels := []string{
@@ -155,7 +394,144 @@ func getPlayerModel(playerIndex int) string {
 	return modelName
 }
 
+// BuildPlayerConfigs creates the configuration of every seat from the
+// command-line flags
+func BuildPlayerConfigs() ([]*PlayerConfig, error) {
+	prompts := make(map[string]*PromptTemplate)
+	libraries := make(map[string]*ExampleLibrary)
+	output, err := ParseOutputMode(outputName)
+	if err != nil {
+		return nil, err
+	}
+	selection, err := ParseExampleSelection(exampleSelect)
+	if err != nil {
+		return nil, err
+	}
+
+	players := make([]*PlayerConfig, numPlayers)
+	for i := range players {
+		spec := defaultSections
+		if playerSections[i] != "" {
+			spec = playerSections[i]
+		}
+		sections, canonical, err := ParseSections(spec)
+		if err != nil {
+			return nil, fmt.Errorf("player %s: %w", PlayerIDs[i], err)
+		}
+
+		players[i] = &PlayerConfig{
+			Model:       getPlayerModel(i),
+			Temperature: temperature,
+			Sections:    sections,
+			Reasoning:   reasoning,
+			Agent:       agentMode,
+		}
+
+		var variant []string
+		if reasoning {
+			variant = append(variant, "reasoning")
+		}
+		if agentMode {
+			variant = append(variant, "agent")
+		}
+		if canonical != "" {
+			variant = append(variant, "sections="+canonical)
+		}
+		if output != OutputText && backend.Supports(output) {
+			players[i].Output = output
+			variant = append(variant, "output="+string(output))
+		}
+
+		memoryName := defaultMemory
+		if playerMemories[i] != "" {
+			memoryName = playerMemories[i]
+		}
+		memory, err := ParseMemoryMode(memoryName)
+		if err != nil {
+			return nil, fmt.Errorf("player %s: %w", PlayerIDs[i], err)
+		}
+		if memory != MemoryOff {
+			if memoryTurns < 1 {
+				return nil, fmt.Errorf("-memory-turns must be at least 1")
+			}
+			if _, ok := backend.(ChatBackend); !ok {
+				return nil, fmt.Errorf("player %s: the %s API does not support conversation memory", PlayerIDs[i], backend.Name())
+			}
+			players[i].Memory = memory
+			players[i].MemoryTurns = memoryTurns
+			variant = append(variant, fmt.Sprintf("memory=%s%d", memory, memoryTurns))
+		}
+
+		boardName := defaultBoard
+		if playerBoards[i] != "" {
+			boardName = playerBoards[i]
+		}
+		board, err := ParseBoardEncoding(boardName)
+		if err != nil {
+			return nil, fmt.Errorf("player %s: %w", PlayerIDs[i], err)
+		}
+		if board != EncodingGrid {
+			players[i].Board = board
+			variant = append(variant, "board="+string(board))
+		}
+
+		examplesPath := defaultExamples
+		if playerExamples[i] != "" {
+			examplesPath = playerExamples[i]
+		}
+		if examplesPath != "" {
+			if exampleCount < 1 {
+				return nil, fmt.Errorf("-example-count must be at least 1")
+			}
+			library, ok := libraries[examplesPath]
+			if !ok {
+				library, err = LoadExamples(examplesPath)
+				if err != nil {
+					return nil, fmt.Errorf("player %s: %w", PlayerIDs[i], err)
+				}
+				libraries[examplesPath] = library
+			}
+			players[i].Examples = library
+			players[i].ExampleSelect = selection
+			players[i].ExampleCount = exampleCount
+			variant = append(variant, fmt.Sprintf("examples=%s@%s:%s%d", library.Name, library.Version, selection, exampleCount))
+		}
+
+		path := defaultPromptPath
+		if playerPrompts[i] != "" {
+			path = playerPrompts[i]
+		}
+		if path != "" {
+			prompt, ok := prompts[path]
+			if !ok {
+				prompt, err = LoadPrompt(path)
+				if err != nil {
+					return nil, fmt.Errorf("player %s: %w", PlayerIDs[i], err)
+				}
+				prompts[path] = prompt
+			}
+			players[i].Prompt = prompt
+			if prompt.Version != defaultPrompt.Version {
+				variant = append(variant, fmt.Sprintf("prompt=%s@%s", prompt.Name, prompt.Version))
+			}
+		}
+		players[i].Variant = strings.Join(variant, " ")
+	}
+	return players, nil
+}
+
 func main() {
+	// Subcommands work on stored results and take their own flags
+	if len(os.Args) > 1 {
+		if command, ok := subcommands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	flag.Parse()
 
 	// Validate number of players
@@ -164,109 +540,265 @@ func main() {
 		return
 	}
 
+	var err error
+	if arenaPath != "" {
+		if arena, err = LoadArena(arenaPath); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		boardWidth, boardHeight = arena.Width, arena.Height
+	}
+	if boardWidth == 0 {
+		boardWidth = gridSize
+	}
+	if boardHeight == 0 {
+		boardHeight = gridSize
+	}
+	if boardWidth < 1 || boardHeight < 1 {
+		fmt.Printf("Error: Board width and height must be positive (got %dx%d)\n", boardWidth, boardHeight)
+		return
+	}
+	if obstacles < 0 || obstacles > 0.5 {
+		fmt.Printf("Error: Obstacle density must be between 0 and 0.5 (got %g)\n", obstacles)
+		return
+	}
+	if sprtMode && (sprtP1 <= 0.5 || sprtP1 >= 1) {
+		fmt.Printf("Error: -sprt-p1 must be above 0.5 and below 1 (got %g)\n", sprtP1)
+		return
+	}
+	if sprtMode && (sprtAlpha <= 0 || sprtAlpha >= 1 || sprtBeta <= 0 || sprtBeta >= 1) {
+		fmt.Printf("Error: -sprt-alpha and -sprt-beta must be between 0 and 1 (got %g and %g)\n", sprtAlpha, sprtBeta)
+		return
+	}
+	if topology, err = ParseTopology(topologyName); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if geometry, err = ParseGeometry(geometryName); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if verbosity, err = ParseVerbosity(verbosityName); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
 	fmt.Println("🐍 Welcome to LLM Snakes Game! 🐍")
-	fmt.Printf("Grid Size: %dx%d\n", gridSize, gridSize)
+	fmt.Printf("Grid Size: %dx%d\n", boardWidth, boardHeight)
+	if arena != nil {
+		fmt.Printf("Arena: %s\n", arena.Label())
+	}
+	if obstacles > 0 {
+		fmt.Printf("Obstacles: %.0f%% of free cells\n", obstacles*100)
+	}
+	if topology == TopologyTorus {
+		fmt.Println("Topology: torus (edges wrap around)")
+	}
+	if geometry == GeometryHex {
+		fmt.Println("Geometry: hex (six directions)")
+	}
 	fmt.Printf("Players: %d\n", numPlayers)
 
+	backend, err = NewBackend(apiName, llmURL)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	if _, ok := backend.(ChatBackend); agentMode && !ok {
+		fmt.Printf("Error: the %s API does not support tool calling\n", backend.Name())
+		return
+	}
+
 	// Display model configuration
+	players, err := BuildPlayerConfigs()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
 	fmt.Println("Models:")
-	for i := 0; i < numPlayers; i++ {
-		playerID := PlayerIDs[i]
-		model := getPlayerModel(i)
-		fmt.Printf("  Player %s: %s\n", playerID, model)
+	models := make(map[string]bool)
+	for i, player := range players {
+		fmt.Printf("  Player %s: %s\n", PlayerIDs[i], player.Label())
+		models[player.Label()] = true
+	}
+	if sprtMode && len(models) != 2 {
+		fmt.Printf("Error: -sprt compares exactly two distinct models (got %d)\n", len(models))
+		return
 	}
 
-	fmt.Printf("API URL: %s\n\n", llmURL)
+	if mode, _ := ParseOutputMode(outputName); mode != OutputText && !backend.Supports(mode) {
+		fmt.Printf("Note: the %s API does not support %s output; parsing free text instead\n", backend.Name(), mode)
+	}
+	fmt.Printf("API URL: %s (%s)\n\n", backend.URL(), backend.Name())
 
-	stats := &GameStats{
-		PlayerWins:      make(map[string]int),
-		ResponseTimes:   make([]float64, 0),
-		MinResponseTime: 999999,
-		MaxResponseTime: 0,
+	// The random suffix keeps runs started within the same second apart
+	runID := fmt.Sprintf("%s-%04x", time.Now().Format("20060102-150405"), rand.Intn(1<<16))
+	sinks, err := OpenResultSinks()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer CloseResultSinks(sinks)
+	if dbPath != "" {
+		fmt.Printf("Saving results to %s (run %s)\n\n", dbPath, runID)
+	}
+
+	if mirrorMode {
+		if numPlayers > mirrorMax {
+			fmt.Printf("Warning: %d players exceed -mirror-max %d, so only the %d seat rotations are played, not all %d! seat orders\n",
+				numPlayers, mirrorMax, numPlayers, numPlayers)
+		}
+		fmt.Printf("Mirror mode: each setup is played %d times with seats permuted\n\n", len(seatPermutations(numPlayers, mirrorMax)))
 	}
 
+	stats := NewGameStats()
+
+	// Started before the TUI so its address stays on the screen
+	var spectator *Spectator
+	if serveAddr != "" {
+		if spectator, err = StartSpectator(serveAddr); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		defer spectator.Close()
+	}
+
+	renderer = NewTextRenderer(verbosity)
+	if tuiMode {
+		tui = StartTUI(tuiDelay)
+		defer tui.Close()
+		renderer = tui
+	}
+	if spectator != nil {
+		renderer = multiRenderer{renderer, spectator}
+	}
+
+	// In mirror mode -games counts setups, otherwise every setup is one game.
+	// A single game shows its result instead of statistics, except when
+	// silent, where the statistics are the only output.
+	showStats := numGames != 1 || mirrorMode || verbosity == VerbositySilent
 	gameCount := 0
-	for {
-		if numGames > 0 && gameCount >= numGames {
-			break
+	sprtVerdict := ""
+	for setupCount := 1; numGames == 0 || setupCount <= numGames; setupCount++ {
+		setup := NewRandomSetup(players)
+		setups := []*GameSetup{setup}
+		if mirrorMode {
+			setups = MirrorSetup(setup)
 		}
+		setupResult := NewSetupResult(setupCount, setup)
 
-		gameCount++
-		fmt.Printf("\n========== Game %d ==========\n", gameCount)
+		for _, s := range setups {
+			gameCount++
+			record := PlayGame(gameCount, s)
 
-		result := PlayGame(gameCount)
+			// Update statistics
+			RecordGameResult(stats, record)
+			setupResult.Record(record)
 
-		// Update statistics
-		stats.TotalGames++
-		if result == "error" {
-			stats.Errors++
-		} else if result != "" {
-			stats.PlayerWins[result]++
+			for _, sink := range sinks {
+				if err := sink.WriteGame(runID, record); err != nil {
+					renderer.Warning(fmt.Sprintf("❌ Error saving game: %v", err))
+				}
+			}
+
+			// Display current statistics
+			if showStats {
+				renderer.Stats(stats)
+			}
 		}
 
-		// Display current statistics
-		if numGames != 1 {
-			DisplayStats(stats)
+		if mirrorMode {
+			stats.Setups = append(stats.Setups, setupResult)
+			renderer.SetupResult(setupResult)
+		}
+
+		// Checked per setup so mirrored games are never cut in half
+		if sprtMode {
+			var stop bool
+			if stop, sprtVerdict = CheckSPRT(stats); stop {
+				break
+			}
 		}
 	}
 
-	if numGames != 1 {
+	if tui != nil {
+		tui.Close()
+	}
+
+	if showStats {
 		fmt.Println("\n" + strings.Repeat("=", 50))
 		fmt.Println("Final Statistics:")
 		DisplayStats(stats)
+		DisplaySetupSummary(stats.Setups)
+		if sprtVerdict != "" {
+			fmt.Printf("SPRT stopped the match: %s\n", sprtVerdict)
+		}
 	}
 }
 
-// InitGame creates a new game state with random starting positions
-func InitGame() *GameState {
-	game := &GameState{
-		Size:          gridSize,
-		NumPlayers:    numPlayers,
-		Grid:          make([][]string, gridSize),
-		PlayerPos:     make(map[string]Position),
-		PlayerConfigs: make(map[string]*PlayerConfig),
-		ActivePlayers: make(map[string]bool),
-		Visited:       make(map[Position]bool),
-		Moves:         make([]Move, 0),
-	}
-
-	// Initialize player configurations
-	for i := 0; i < numPlayers; i++ {
-		playerID := PlayerIDs[i]
-		game.PlayerConfigs[playerID] = &PlayerConfig{
-			ID:          playerID,
-			Model:       getPlayerModel(i),
-			Temperature: temperature,
+// NewRandomSetup lays out the arena and random obstacles, picks random
+// starting positions at least 3 cells apart for players without a spawn
+// point and seats the players in order
+func NewRandomSetup(players []*PlayerConfig) *GameSetup {
+	setup := &GameSetup{
+		Seed:           rand.Int63(),
+		Width:          boardWidth,
+		Height:         boardHeight,
+		Topology:       topology,
+		Geometry:       geometry,
+		StartPositions: make([]Position, 0, len(players)),
+		Players:        players,
+	}
+	rng := rand.New(rand.NewSource(setup.Seed))
+	board := &GameState{Width: setup.Width, Height: setup.Height, Topology: setup.Topology, Geometry: setup.Geometry}
+
+	spawns := make(map[Position]bool)
+	if arena != nil {
+		setup.Arena = arena.Label()
+		setup.Walls = append(setup.Walls, arena.Walls...)
+		setup.Blocked = append(setup.Blocked, arena.Blocked...)
+		for i := range players {
+			if pos, ok := arena.Spawns[PlayerIDs[i]]; ok {
+				spawns[pos] = true
+			}
 		}
 	}
-
-	// Initialize empty grid
-	for i := 0; i < gridSize; i++ {
-		game.Grid[i] = make([]string, gridSize)
-		for j := 0; j < gridSize; j++ {
-			game.Grid[i][j] = Empty
+	if obstacles > 0 {
+		if setup.Arena != "" {
+			setup.Arena += " "
 		}
+		setup.Arena += fmt.Sprintf("obstacles=%g", obstacles)
+		addObstacles(setup, obstacles, spawns, rng)
 	}
+	occupied := obstacleGrid(setup)
 
-	for i := 0; i < numPlayers; i++ {
-		playerID := PlayerIDs[i]
+	for i := range players {
 		var pos Position
+		if arena != nil {
+			if spawn, ok := arena.Spawns[PlayerIDs[i]]; ok {
+				occupied[spawn.Row][spawn.Col] = true
+				setup.StartPositions = append(setup.StartPositions, spawn)
+				continue
+			}
+		}
 
-		// Keep trying positions until we find one that's far enough from all existing players
+		// Keep trying positions until we find one that's free and far enough from all existing players
 		maxAttempts := 1000
 		for attempt := 0; attempt < maxAttempts; attempt++ {
 			pos = Position{
-				Row: rand.Intn(gridSize),
-				Col: rand.Intn(gridSize),
+				Row: rng.Intn(boardHeight),
+				Col: rng.Intn(boardWidth),
+			}
+			if occupied[pos.Row][pos.Col] {
+				continue
 			}
 
 			// Check if position is far enough from all existing players
 			tooClose := false
-			for existingPlayer, existingPos := range game.PlayerPos {
-				_ = existingPlayer
-				rowDiff := abs(pos.Row - existingPos.Row)
-				colDiff := abs(pos.Col - existingPos.Col)
-				if rowDiff+colDiff < 3 {
+			for _, existingPos := range setup.StartPositions {
+				if board.Distance(pos, existingPos) < 3 {
 					tooClose = true
 					break
 				}
@@ -276,6 +808,79 @@ func InitGame() *GameState {
 				break
 			}
 		}
+		if occupied[pos.Row][pos.Col] {
+			pos = firstFreeCell(occupied)
+		}
+
+		occupied[pos.Row][pos.Col] = true
+		setup.StartPositions = append(setup.StartPositions, pos)
+	}
+
+	return setup
+}
+
+// firstFreeCell returns the first free cell in reading order, the fallback
+// start position when random draws keep hitting occupied cells
+func firstFreeCell(occupied [][]bool) Position {
+	for row := range occupied {
+		for col := range occupied[row] {
+			if !occupied[row][col] {
+				return Position{row, col}
+			}
+		}
+	}
+	return Position{}
+}
+
+// InitGame creates a new game state from a setup
+func InitGame(setup *GameSetup) *GameState {
+	game := &GameState{
+		Width:         setup.Width,
+		Height:        setup.Height,
+		Topology:      setup.Topology,
+		Geometry:      setup.Geometry,
+		NumPlayers:    len(setup.Players),
+		Grid:          make([][]string, setup.Height),
+		PlayerPos:     make(map[string]Position),
+		PlayerConfigs: make(map[string]*PlayerConfig),
+		ActivePlayers: make(map[string]bool),
+		Visited:       make(map[Position]bool),
+		Moves:         make([]Move, 0),
+		EliminatedAt:  make(map[string]int),
+		Memories:      make(map[string]*Memory),
+	}
+
+	// Initialize player configurations
+	for i, player := range setup.Players {
+		config := *player
+		config.ID = PlayerIDs[i]
+		game.PlayerConfigs[config.ID] = &config
+		if memory := NewMemory(&config); memory != nil {
+			game.Memories[config.ID] = memory
+		}
+	}
+
+	// Initialize empty grid
+	for i := 0; i < game.Height; i++ {
+		game.Grid[i] = make([]string, game.Width)
+		for j := 0; j < game.Width; j++ {
+			game.Grid[i][j] = Empty
+		}
+	}
+
+	// Walls and blocked cells can never be entered
+	for _, pos := range setup.Walls {
+		game.Grid[pos.Row][pos.Col] = Wall
+		game.Visited[pos] = true
+	}
+	for _, pos := range setup.Blocked {
+		game.Grid[pos.Row][pos.Col] = Blocked
+		game.Visited[pos] = true
+	}
+
+	for i := range setup.Players {
+		playerID := PlayerIDs[i]
+		pos := setup.StartPositions[i]
 
 		game.PlayerPos[playerID] = pos
 		game.ActivePlayers[playerID] = true
@@ -283,22 +888,31 @@ func InitGame() *GameState {
 		game.Visited[pos] = true
 	}
 
+	game.CenterNorm = maxCenterDistance(game)
 	return game
 }
 
-// PlayGame runs a single game and returns the winner
-func PlayGame(gameNumber int) string {
-	game := InitGame()
-
-	fmt.Printf("\nGame %d. Starting positions:\n", gameNumber)
-	for i := 0; i < game.NumPlayers; i++ {
-		playerID := PlayerIDs[i]
-		pos := game.PlayerPos[playerID]
-		fmt.Printf("Player %s: (%d, %d)\n", playerID, pos.Row, pos.Col)
+// PlayGame runs a single game and returns its record
+func PlayGame(gameNumber int, setup *GameSetup) *GameRecord {
+	game := InitGame(setup)
+	record := &GameRecord{
+		Number:        gameNumber,
+		StartedAt:     time.Now(),
+		Setup:         setup,
+		Width:         game.Width,
+		Height:        game.Height,
+		PromptVersion: setup.PromptVersion(),
+	}
+	finish := func(winner string) *GameRecord {
+		record.Winner = winner
+		record.Duration = time.Since(record.StartedAt).Seconds()
+		record.Moves = game.Moves
+		record.EliminatedAt = game.EliminatedAt
+		renderer.GameOver(game, record)
+		return record
 	}
-	fmt.Println()
 
-	DisplayBoard(game)
+	renderer.GameStarted(game, gameNumber)
 
 	currentPlayerIndex := 0
 	moveCount := 0
@@ -328,15 +942,12 @@ func PlayGame(gameNumber int) string {
 
 		if activeCount <= 1 {
 			if activeCount == 1 {
-				fmt.Printf("\n🎉 Player %s wins! All other players have been eliminated.\n", lastActivePlayer)
-				return lastActivePlayer
+				return finish(lastActivePlayer)
 			}
-			fmt.Println("\n🤝 Draw! All players eliminated simultaneously.")
-			return ""
+			return finish("")
 		}
 
 		moveCount++
-		fmt.Printf("\n--- Move %d: Player %s's turn ---\n", moveCount, currentPlayer)
 
 		// Get valid moves for current player
 		validMoves := GetValidMoves(game, currentPlayer)
@@ -344,7 +955,8 @@ func PlayGame(gameNumber int) string {
 		if len(validMoves) == 0 {
 			// Current player has no valid moves - they're eliminated
 			game.ActivePlayers[currentPlayer] = false
-			fmt.Printf("❌ Player %s is eliminated (no valid moves)\n", currentPlayer)
+			game.EliminatedAt[currentPlayer] = moveCount
+			renderer.Eliminated(game, currentPlayer, moveCount)
 
 			// Move to next player
 			currentPlayerIndex = (currentPlayerIndex + 1) % game.NumPlayers
@@ -352,19 +964,35 @@ func PlayGame(gameNumber int) string {
 		}
 
 		// Get move from LLM
-		direction, responseTime, err := GetLLMMove(game, currentPlayer, validMoves)
+		renderer.Thinking(game, currentPlayer, moveCount)
+		decision, err := GetLLMMove(game, currentPlayer, validMoves)
 
 		if err != nil {
-			fmt.Printf("❌ Error getting move from LLM: %v\n", err)
-			return "error"
+			record.Error = err.Error()
+			return finish("error")
 		}
 
-		fmt.Printf("Player %s chose: %s (%.2fs)\n", currentPlayer, direction, responseTime)
-
-		// Make the move
-		MakeMove(game, currentPlayer, direction)
-
-		DisplayBoard(game)
+		// Rank the options before the board changes
+		evaluations := RankMoves(game, currentPlayer, validMoves)
+		rank := engineRank(evaluations, decision.Direction)
+
+		// Make the move and annotate it with how it was chosen
+		MakeMove(game, currentPlayer, decision.Direction)
+		move := &game.Moves[len(game.Moves)-1]
+		move.PromptHash = decision.PromptHash
+		move.Response = decision.Response
+		move.Reasoning = decision.Reasoning
+		move.ToolCalls = decision.ToolCalls
+		move.Attempts = decision.Attempts
+		move.Latency = decision.Latency
+		move.Retries = decision.Retries
+		move.Ambiguous = decision.Ambiguous
+		move.EngineRank = rank
+		move.NumOptions = len(validMoves)
+		move.SafetyLevel = evaluations[rank-1].SafetyLevel
+		move.BestSafety = evaluations[0].SafetyLevel
+
+		renderer.Moved(game, move)
 
 		// Move to next player
 		currentPlayerIndex = (currentPlayerIndex + 1) % game.NumPlayers
@@ -378,29 +1006,27 @@ func GetValidMoves(game *GameState, player string) []Direction {
 	validMoves := make([]Direction, 0)
 
 	// Check each direction
-	directions := []struct {
-		dir    Direction
-		newPos Position
-	}{
-		{Up, Position{currentPos.Row - 1, currentPos.Col}},
-		{Down, Position{currentPos.Row + 1, currentPos.Col}},
-		{Left, Position{currentPos.Row, currentPos.Col - 1}},
-		{Right, Position{currentPos.Row, currentPos.Col + 1}},
-	}
-
-	for _, d := range directions {
-		if IsValidMove(game, d.newPos) {
-			validMoves = append(validMoves, d.dir)
+	for _, dir := range game.Directions() {
+		if IsValidMove(game, getNewPosition(game, currentPos, dir)) {
+			validMoves = append(validMoves, dir)
 		}
 	}
 
 	return validMoves
 }
 
-// IsValidMove checks if a position is valid (in bounds and not visited)
+// InBounds reports whether a position is on the board
+func (g *GameState) InBounds(pos Position) bool {
+	return pos.Row >= 0 && pos.Row < g.Height && pos.Col >= 0 && pos.Col < g.Width
+}
+
+// IsValidMove checks if a position is valid (in bounds and not visited).
+// On a torus every position is wrapped onto the board first.
 func IsValidMove(game *GameState, pos Position) bool {
+	pos = game.Wrap(pos)
+
 	// Check bounds
-	if pos.Row < 0 || pos.Row >= game.Size || pos.Col < 0 || pos.Col >= game.Size {
+	if !game.InBounds(pos) {
 		return false
 	}
 
@@ -429,17 +1055,7 @@ func MakeMove(game *GameState, player string, direction Direction) {
 	oldPos := currentPos
 
 	// Calculate new position
-	newPos := currentPos
-	switch direction {
-	case Up:
-		newPos.Row--
-	case Down:
-		newPos.Row++
-	case Left:
-		newPos.Col--
-	case Right:
-		newPos.Col++
-	}
+	newPos := getNewPosition(game, currentPos, direction)
 
 	// Mark old position as trail
 	game.Grid[oldPos.Row][oldPos.Col] = trailChar
@@ -462,37 +1078,42 @@ func MakeMove(game *GameState, player string, direction Direction) {
 // DisplayBoard shows the current game state
 func DisplayBoard(game *GameState) {
 	fmt.Println()
+	if game.Geometry == GeometryHex {
+		fmt.Print(formatHexBoard(game, hexGlyph(game)))
+		displayLegend(game)
+		return
+	}
 
 	// Top border with column numbers
 	fmt.Print("    ")
-	for col := 0; col < game.Size; col++ {
+	for col := 0; col < game.Width; col++ {
 		fmt.Printf("%2d  ", col)
 	}
 	fmt.Println()
 
 	fmt.Print("   ┌")
-	for col := 0; col < game.Size; col++ {
+	for col := 0; col < game.Width; col++ {
 		fmt.Print("───")
-		if col < game.Size-1 {
+		if col < game.Width-1 {
 			fmt.Print("┬")
 		}
 	}
 	fmt.Println("┐")
 
 	// Grid rows
-	for row := 0; row < game.Size; row++ {
+	for row := 0; row < game.Height; row++ {
 		fmt.Printf("%2d │", row)
-		for col := 0; col < game.Size; col++ {
+		for col := 0; col < game.Width; col++ {
 			fmt.Printf(" %s │", game.Grid[row][col])
 		}
 		fmt.Println()
 
 		// Row separator
-		if row < game.Size-1 {
+		if row < game.Height-1 {
 			fmt.Print("   ├")
-			for col := 0; col < game.Size; col++ {
+			for col := 0; col < game.Width; col++ {
 				fmt.Print("───")
-				if col < game.Size-1 {
+				if col < game.Width-1 {
 					fmt.Print("┼")
 				}
 			}
@@ -502,15 +1123,19 @@ func DisplayBoard(game *GameState) {
 
 	// Bottom border
 	fmt.Print("   └")
-	for col := 0; col < game.Size; col++ {
+	for col := 0; col < game.Width; col++ {
 		fmt.Print("───")
-		if col < game.Size-1 {
+		if col < game.Width-1 {
 			fmt.Print("┴")
 		}
 	}
 	fmt.Println("┘")
 
-	// Legend
+	displayLegend(game)
+}
+
+// displayLegend explains the board glyphs below DisplayBoard
+func displayLegend(game *GameState) {
 	fmt.Print("\nLegend: ")
 	for i := 0; i < game.NumPlayers; i++ {
 		playerID := PlayerIDs[i]
@@ -520,247 +1145,135 @@ func DisplayBoard(game *GameState) {
 		}
 		fmt.Printf("%s=Player%s %s=Trail", playerID, playerID, trailChar)
 	}
+	if len(gridCells(game, Wall)) > 0 {
+		fmt.Print("  " + Wall + "=Wall")
+	}
+	if len(gridCells(game, Blocked)) > 0 {
+		fmt.Print("  " + Blocked + "=Blocked")
+	}
 	fmt.Println()
 }
 
 // GetLLMMove gets a move from the LLM
-func GetLLMMove(game *GameState, player string, validMoves []Direction) (Direction, float64, error) {
-	prompt := BuildPrompt(game, player, validMoves)
+func GetLLMMove(game *GameState, player string, validMoves []Direction) (*LLMDecision, error) {
+	prompt, err := BuildPrompt(game, player, validMoves)
+	if err != nil {
+		return nil, err
+	}
+	promptHash := hashPrompt(prompt)
 
+	renderer.Prompted(game, player, prompt)
 	if debugMode {
-		fmt.Println("\n=== PROMPT ===")
-		fmt.Println(prompt)
-		fmt.Println("=== END PROMPT ===\n")
+		renderer.Debug("PROMPT", prompt)
 	}
 
 	playerConfig := game.PlayerConfigs[player]
+	if playerConfig.Agent {
+		return GetAgentMove(game, player, validMoves, prompt)
+	}
 
-	for retry := 0; retry < maxRetries; retry++ {
-		if retry > 0 {
-			fmt.Printf("Retry %d/%d...\n", retry, maxRetries)
-		}
+	// A rejected response is answered with a corrective turn, leaving the
+	// prompt itself unchanged. Chat backends continue the conversation;
+	// others get the prompt again with the correction appended.
+	memory := game.Memories[player]
+	_, canChat := backend.(ChatBackend)
+	messages := []ChatMessage{{Role: "user", Content: prompt}}
+	if memory != nil {
+		messages = memory.Messages(prompt)
+	}
+	nextPrompt := prompt
+	var attempts []Attempt
+	ambiguous := 0
 
+	for retry := 0; retry < maxRetries; retry++ {
 		start := time.Now()
-		response, err := CallLLM(prompt, playerConfig)
+		var response string
+		if memory != nil || (retry > 0 && canChat) {
+			response, err = CallChat(messages, playerConfig, validMoves)
+		} else {
+			response, err = CallLLM(nextPrompt, playerConfig, validMoves)
+		}
 		responseTime := time.Since(start).Seconds()
 
 		if err != nil {
-			return "", 0, err
+			return nil, err
 		}
 
-		direction, err := ParseDirection(response, validMoves)
+		direction, thoughts, err := parseMove(response, moveTargets(game, player), validMoves, playerConfig)
 		if err == nil {
-			return direction, responseTime, nil
-		}
-
-		fmt.Printf("Invalid response: %s (Error: %v)\n", response, err)
-		prompt = prompt + fmt.Sprintf("\n\nYour previous response '%s' was invalid. Please respond with exactly one word: %s",
-			response, formatValidMoves(validMoves))
-	}
-
-	return "", 0, fmt.Errorf("max retries exceeded")
-}
-
-// BuildPrompt creates the prompt for the LLM
-func BuildPrompt(game *GameState, player string, validMoves []Direction) string {
-	var buf bytes.Buffer
-
-	buf.WriteString(fmt.Sprintf("You are playing a Snakes game as Player %s.\n\n", player))
-
-	buf.WriteString("GAME RULES:\n")
-	if game.NumPlayers == 2 {
-		buf.WriteString("- This is a 2-player grid-based game\n")
-	} else {
-		buf.WriteString(fmt.Sprintf("- This is a %d-player grid-based game\n", game.NumPlayers))
-	}
-	buf.WriteString("- Each player moves one cell at a time: up, down, left, or right\n")
-	buf.WriteString("- Each cell you visit becomes part of your trail and can NEVER be visited again by anyone\n")
-	buf.WriteString("- You LOSE if you have no valid moves (all adjacent cells are visited or out of bounds)\n")
-	buf.WriteString("- Your goal: survive longer than your opponents\n\n")
-
-	// Move history (limit to last 20 moves to keep prompt manageable)
-	if len(game.Moves) > 0 {
-		buf.WriteString("RECENT MOVE HISTORY:\n")
-		startIdx := 0
-		if len(game.Moves) > 20 {
-			startIdx = len(game.Moves) - 20
-		}
-		for i := startIdx; i < len(game.Moves); i++ {
-			move := game.Moves[i]
-			buf.WriteString(fmt.Sprintf("%d. Player %s moved %s from (%d,%d) to (%d,%d)\n",
-				i+1, move.Player, move.Direction, move.From.Row, move.From.Col, move.To.Row, move.To.Col))
-		}
-		buf.WriteString("\n")
-	}
-
-	// Current positions
-	buf.WriteString("CURRENT POSITIONS:\n")
-	buf.WriteString(fmt.Sprintf("- You (Player %s): (%d, %d)\n", player,
-		game.PlayerPos[player].Row, game.PlayerPos[player].Col))
-
-	// List all opponents
-	for i := 0; i < game.NumPlayers; i++ {
-		opponentID := PlayerIDs[i]
-		if opponentID != player && game.ActivePlayers[opponentID] {
-			pos := game.PlayerPos[opponentID]
-			buf.WriteString(fmt.Sprintf("- Player %s: (%d, %d)\n", opponentID, pos.Row, pos.Col))
-		} else if opponentID != player && !game.ActivePlayers[opponentID] {
-			buf.WriteString(fmt.Sprintf("- Player %s: ELIMINATED\n", opponentID))
-		}
-	}
-	buf.WriteString("\n")
-
-	// Current board
-	buf.WriteString("CURRENT BOARD:\n")
-	buf.WriteString(formatBoardForPrompt(game))
-	buf.WriteString("\n")
-
-	// Valid moves with deep look-ahead analysis
-	buf.WriteString("YOUR VALID MOVES (with deep strategic analysis):\n")
-	if len(validMoves) == 0 {
-		buf.WriteString("NONE - You lose!\n")
-	} else {
-		// Evaluate all moves and sort by score
-		evaluations := make([]MoveEvaluation, 0, len(validMoves))
-		for _, dir := range validMoves {
-			eval := evaluateMove(game, getPlayerPos(game, player), dir)
-			evaluations = append(evaluations, eval)
-		}
-
-		// Sort by score (descending)
-		for i := 0; i < len(evaluations)-1; i++ {
-			for j := i + 1; j < len(evaluations); j++ {
-				if evaluations[j].TotalScore > evaluations[i].TotalScore {
-					evaluations[i], evaluations[j] = evaluations[j], evaluations[i]
+			attempts = append(attempts, Attempt{Response: response, Latency: responseTime})
+			if debugMode && thoughts != "" {
+				renderer.Debug("REASONING", thoughts)
+			}
+			if memory != nil {
+				if err := memory.Remember(playerConfig, prompt, response); err != nil {
+					return nil, err
 				}
 			}
+			return &LLMDecision{
+				Direction:  direction,
+				Response:   response,
+				Reasoning:  thoughts,
+				PromptHash: promptHash,
+				Latency:    responseTime,
+				Retries:    retry,
+				Ambiguous:  ambiguous,
+				Attempts:   attempts,
+			}, nil
 		}
 
-		// Display in ranked order
-		for rank, eval := range evaluations {
-			rankEmoji := "  "
-			if rank == 0 {
-				rankEmoji = "⭐"
-			} else if rank == len(evaluations)-1 && len(evaluations) > 1 {
-				rankEmoji = "⚠️ "
-			}
-			buf.WriteString(fmt.Sprintf("%s %s → (%d,%d) | Score: %.1f | %s\n",
-				rankEmoji,
-				strings.ToUpper(string(eval.Direction)),
-				eval.NewPos.Row, eval.NewPos.Col,
-				eval.TotalScore,
-				eval.SafetyLevel))
-			buf.WriteString(fmt.Sprintf("   ├─ Next moves: %d | Territory: %d cells | Future mobility: %.1f\n",
-				eval.ImmediateMoves,
-				eval.ReachableTerritory,
-				eval.AvgDepthMobility))
+		attempt := rejectedAttempt(response, responseTime, err)
+		attempts = append(attempts, attempt)
+		if isAmbiguous(err) {
+			ambiguous++
 		}
-	}
-	buf.WriteString("\n")
+		renderer.Rejected(game, player, attempt, retry+1)
 
-	// Blocked moves
-	blockedMoves := getBlockedMoves(game, player, validMoves)
-	if len(blockedMoves) > 0 {
-		buf.WriteString("BLOCKED MOVES:\n")
-		for dir, reason := range blockedMoves {
-			buf.WriteString(fmt.Sprintf("⛔ %s - %s\n", strings.ToUpper(string(dir)), reason))
+		fix := correction(game, player, validMoves, err)
+		if debugMode {
+			renderer.Debug("", "Correction: "+fix)
 		}
-		buf.WriteString("\n")
+		messages = append(messages,
+			ChatMessage{Role: "assistant", Content: response},
+			ChatMessage{Role: "user", Content: fix})
+		nextPrompt = retryPrompt(prompt, response, fix, playerConfig.Reasoning)
 	}
 
-	// Strategy hints
-	buf.WriteString("CRITICAL STRATEGY:\n")
-	buf.WriteString("⭐ The moves are RANKED BY SCORE - higher score = better long-term survival\n")
-	buf.WriteString("⭐ The top-ranked move (⭐) is calculated to give you the best chance to win\n")
-	buf.WriteString("⭐ STRONGLY PREFER moves marked EXCELLENT or GOOD\n\n")
-	buf.WriteString("Key Metrics Explained:\n")
-	buf.WriteString("• Score: Overall quality (immediate + future mobility + territory control)\n")
-	buf.WriteString("• Next moves: Options available after this move (0 = instant death next turn!)\n")
-	buf.WriteString("• Territory: Total reachable space from this position (higher = more room to maneuver)\n")
-	buf.WriteString("• Future mobility: Average options 2-3 moves ahead (higher = better long-term position)\n\n")
-	buf.WriteString("Safety Ratings:\n")
-	buf.WriteString("• EXCELLENT: Large territory + multiple options = strong survival chance\n")
-	buf.WriteString("• GOOD: Decent space and mobility = reasonable position\n")
-	buf.WriteString("• MODERATE: Limited but viable = be cautious\n")
-	buf.WriteString("• RISKY: Very limited options = may trap yourself soon\n")
-	buf.WriteString("• DANGEROUS: Poor position = avoid unless it's your only choice\n")
-	buf.WriteString("• DEATH TRAP: 0 next moves = you'll lose on the next turn! NEVER choose this!\n\n")
-
-	// Final instruction
-	buf.WriteString("RESPOND WITH EXACTLY ONE WORD - YOUR CHOSEN DIRECTION:\n")
-	buf.WriteString(fmt.Sprintf("Valid responses: %s\n", formatValidMoves(validMoves)))
-	buf.WriteString("Do NOT include any explanation, punctuation, or other text.\n")
-	buf.WriteString("Just respond with: up, down, left, or right\n")
-
-	return buf.String()
+	return nil, fmt.Errorf("max retries exceeded")
 }
 
-// CallLLM makes the HTTP request to the LLM API
-func CallLLM(prompt string, playerConfig *PlayerConfig) (string, error) {
-	reqBody := OllamaRequest{
+// CallLLM sends the prompt to the LLM backend, constraining the answer to
+// the valid moves in the player's output mode
+func CallLLM(prompt string, playerConfig *PlayerConfig, validMoves []Direction) (string, error) {
+	return backend.Generate(&LLMRequest{
 		Model:       playerConfig.Model,
 		Prompt:      prompt,
-		Stream:      false,
 		Temperature: playerConfig.Temperature,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", err
-	}
-
-	resp, err := http.Post(llmURL, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", err
-	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			fmt.Printf("Error closing response body: %v\n", err)
-		}
-	}(resp.Body)
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
-
-	var ollamaResp OllamaResponse
-	if err := json.Unmarshal(body, &ollamaResp); err != nil {
-		return "", err
-	}
-
-	return strings.TrimSpace(ollamaResp.Response), nil
+		Output:      playerConfig.Output,
+		ValidMoves:  validMoves,
+		Reasoning:   playerConfig.Reasoning,
+	})
 }
 
-// ParseDirection extracts and validates a direction from the LLM response
-func ParseDirection(response string, validMoves []Direction) (Direction, error) {
-	response = strings.ToLower(strings.TrimSpace(response))
-
-	// Try exact match first
-	for _, dir := range validMoves {
-		if response == string(dir) {
-			return dir, nil
+// parseMove extracts the move and any reasoning from a response. Structured
+// answers that fail to decode fall back to the text parsers.
+func parseMove(response string, targets map[Position]Direction, validMoves []Direction, playerConfig *PlayerConfig) (Direction, string, error) {
+	if playerConfig.Output == OutputJSON {
+		if direction, thoughts, err := ParseJSONMove(response, validMoves); err == nil {
+			return direction, thoughts, nil
 		}
 	}
-
-	// Try to extract direction from response using regex
-	pattern := `\b(up|down|left|right)\b`
-	re := regexp.MustCompile(pattern)
-	matches := re.FindStringSubmatch(response)
-
-	if len(matches) > 0 {
-		dir := Direction(matches[1])
-		// Verify it's a valid move
-		for _, validDir := range validMoves {
-			if dir == validDir {
-				return dir, nil
-			}
-		}
-		return "", fmt.Errorf("direction '%s' is not valid", dir)
+	if playerConfig.Reasoning {
+		return ParseReasonedMove(response, targets, validMoves)
 	}
+	direction, err := ParseDirection(response, targets, validMoves)
+	return direction, "", err
+}
 
-	return "", fmt.Errorf("could not parse direction from response")
+// hashPrompt returns a short content hash identifying a prompt
+func hashPrompt(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:8])
 }
 
 // DisplayStats shows game statistics
@@ -776,13 +1289,105 @@ func DisplayStats(stats *GameStats) {
 		if stats.TotalGames > 0 {
 			percentage = float64(wins) / float64(stats.TotalGames) * 100
 		}
-		fmt.Printf("Player %s Wins: %d (%.1f%%)\n", playerID, wins, percentage)
+		fmt.Printf("Player %s Wins: %d (%.1f%%, %s)\n", playerID, wins, percentage,
+			formatInterval(wins, stats.TotalGames))
+	}
+
+	// Per-model wins only tell something new when models differ
+	if len(stats.Models) > 1 {
+		fmt.Println("Wins by model:")
+		for _, model := range stats.Models {
+			wins := stats.ModelWins[model]
+			games := stats.ModelGames[model]
+			percentage := 0.0
+			if games > 0 {
+				percentage = float64(wins) / float64(games) * 100
+			}
+			fmt.Printf("  %s: %d/%d (%.1f%%, %s)\n", model, wins, games, percentage,
+				formatInterval(wins, games))
+		}
+		DisplayHeadToHead(stats)
 	}
+	DisplayHintStats(stats)
 
 	fmt.Printf("Errors: %d\n", stats.Errors)
+	if len(stats.ResponseTimes) > 0 {
+		fmt.Printf("Response time: avg %.2fs, min %.2fs, max %.2fs\n",
+			stats.AvgResponseTime, stats.MinResponseTime, stats.MaxResponseTime)
+	}
 	fmt.Println(strings.Repeat("-", 40))
 }
 
+// NewGameStats creates empty statistics
+func NewGameStats() *GameStats {
+	return &GameStats{
+		PlayerWins:      make(map[string]int),
+		ModelWins:       make(map[string]int),
+		ModelGames:      make(map[string]int),
+		HeadToHead:      make(map[string]map[string]int),
+		Hints:           make(map[string]*HintStats),
+		ResponseTimes:   make([]float64, 0),
+		MinResponseTime: 999999,
+		MaxResponseTime: 0,
+	}
+}
+
+// RecordGameResult updates the statistics with the outcome of one game
+func RecordGameResult(stats *GameStats, record *GameRecord) {
+	setup, result := record.Setup, record.Winner
+	stats.TotalGames++
+
+	for _, move := range record.Moves {
+		model := setup.Players[playerIndex(move.Player)].Label()
+		if stats.Hints[model] == nil {
+			stats.Hints[model] = NewHintStats()
+		}
+		stats.Hints[model].Add(move)
+
+		stats.ResponseTimes = append(stats.ResponseTimes, move.Latency)
+		stats.MinResponseTime = math.Min(stats.MinResponseTime, move.Latency)
+		stats.MaxResponseTime = math.Max(stats.MaxResponseTime, move.Latency)
+	}
+	if len(stats.ResponseTimes) > 0 {
+		total := 0.0
+		for _, t := range stats.ResponseTimes {
+			total += t
+		}
+		stats.AvgResponseTime = total / float64(len(stats.ResponseTimes))
+	}
+
+	// Count each distinct model once per game
+	seen := make(map[string]bool)
+	for _, model := range setup.Labels() {
+		if seen[model] {
+			continue
+		}
+		seen[model] = true
+		if _, ok := stats.ModelGames[model]; !ok {
+			stats.Models = append(stats.Models, model)
+		}
+		stats.ModelGames[model]++
+	}
+
+	if result == "error" {
+		stats.Errors++
+	} else if result != "" {
+		stats.PlayerWins[result]++
+		winner := setup.Players[playerIndex(result)].Label()
+		stats.ModelWins[winner]++
+
+		// The winner beat every other model in the game
+		if stats.HeadToHead[winner] == nil {
+			stats.HeadToHead[winner] = make(map[string]int)
+		}
+		for model := range seen {
+			if model != winner {
+				stats.HeadToHead[winner][model]++
+			}
+		}
+	}
+}
+
 // Helper functions
 
 func abs(x int) int {
@@ -792,30 +1397,41 @@ func abs(x int) int {
 	return x
 }
 
+// playerIndex returns the seat index of a player ID
+func playerIndex(player string) int {
+	for i, id := range PlayerIDs {
+		if id == player {
+			return i
+		}
+	}
+	return -1
+}
+
 func getPlayerPos(game *GameState, player string) Position {
 	return game.PlayerPos[player]
 }
 
-func getNewPosition(pos Position, dir Direction) Position {
-	newPos := pos
-	switch dir {
-	case Up:
-		newPos.Row--
-	case Down:
-		newPos.Row++
-	case Left:
-		newPos.Col--
-	case Right:
-		newPos.Col++
-	}
-	return newPos
+// getNewPosition returns the cell a move leads to, wrapped around the
+// edges on a torus
+func getNewPosition(game *GameState, pos Position, dir Direction) Position {
+	return game.Wrap(stepPosition(pos, dir))
+}
+
+// moveTargets maps the cell every direction leads a player to onto the
+// direction, so answers naming a target cell can be read
+func moveTargets(game *GameState, player string) map[Position]Direction {
+	targets := make(map[Position]Direction)
+	for _, dir := range game.Directions() {
+		targets[getNewPosition(game, game.PlayerPos[player], dir)] = dir
+	}
+	return targets
 }
 
 func getBlockedMoves(game *GameState, player string, validMoves []Direction) map[Direction]string {
 	blocked := make(map[Direction]string)
 	currentPos := getPlayerPos(game, player)
 
-	allDirs := []Direction{Up, Down, Left, Right}
+	allDirs := game.Directions()
 	validMap := make(map[Direction]bool)
 	for _, dir := range validMoves {
 		validMap[dir] = true
@@ -823,14 +1439,22 @@ func getBlockedMoves(game *GameState, player string, validMoves []Direction) map
 
 	for _, dir := range allDirs {
 		if !validMap[dir] {
-			newPos := getNewPosition(currentPos, dir)
+			newPos := getNewPosition(game, currentPos, dir)
 
 			// Check why it's blocked
-			if newPos.Row < 0 || newPos.Row >= game.Size || newPos.Col < 0 || newPos.Col >= game.Size {
+			switch {
+			case !game.InBounds(newPos):
 				blocked[dir] = "out of bounds"
-			} else if game.Visited[newPos] {
+			case game.Grid[newPos.Row][newPos.Col] == Wall:
+				blocked[dir] = "a wall"
+			case game.Grid[newPos.Row][newPos.Col] == Blocked:
+				blocked[dir] = "a blocked cell"
+			case game.Visited[newPos]:
 				blocked[dir] = "already visited"
 			}
+			if reason, ok := blocked[dir]; ok && newPos != stepPosition(currentPos, dir) {
+				blocked[dir] = reason + " across the edge"
+			}
 		}
 	}
 
@@ -846,18 +1470,22 @@ func formatValidMoves(moves []Direction) string {
 }
 
 func formatBoardForPrompt(game *GameState) string {
+	if game.Geometry == GeometryHex {
+		return formatHexBoard(game, hexGlyph(game))
+	}
+
 	var buf bytes.Buffer
 
 	// Column numbers
 	buf.WriteString("    ")
-	for col := 0; col < game.Size; col++ {
+	for col := 0; col < game.Width; col++ {
 		buf.WriteString(fmt.Sprintf("%2d ", col))
 	}
 	buf.WriteString("\n")
 
-	for row := 0; row < game.Size; row++ {
+	for row := 0; row < game.Height; row++ {
 		buf.WriteString(fmt.Sprintf("%2d |", row))
-		for col := 0; col < game.Size; col++ {
+		for col := 0; col < game.Width; col++ {
 			cell := game.Grid[row][col]
 			buf.WriteString(fmt.Sprintf(" %s |", cell))
 		}
@@ -869,15 +1497,8 @@ func formatBoardForPrompt(game *GameState) string {
 
 func countAvailableMoves(game *GameState, pos Position) int {
 	count := 0
-	directions := []Position{
-		{pos.Row - 1, pos.Col}, // up
-		{pos.Row + 1, pos.Col}, // down
-		{pos.Row, pos.Col - 1}, // left
-		{pos.Row, pos.Col + 1}, // right
-	}
-
-	for _, newPos := range directions {
-		if IsValidMove(game, newPos) {
+	for _, dir := range game.Directions() {
+		if IsValidMove(game, getNewPosition(game, pos, dir)) {
 			count++
 		}
 	}
@@ -899,7 +1520,7 @@ type MoveEvaluation struct {
 
 // evaluateMove performs deep analysis of a move
 func evaluateMove(game *GameState, currentPos Position, dir Direction) MoveEvaluation {
-	newPos := getNewPosition(currentPos, dir)
+	newPos := getNewPosition(game, currentPos, dir)
 	eval := MoveEvaluation{
 		Direction: dir,
 		NewPos:    newPos,
@@ -917,25 +1538,61 @@ func evaluateMove(game *GameState, currentPos Position, dir Direction) MoveEvalu
 	// 3. Average mobility at depth 2-3
 	eval.AvgDepthMobility = calculateDepthMobility(simGame, newPos, 2)
 
-	// 4. Distance from center (prefer center positions)
-	centerRow := float64(game.Size) / 2.0
-	centerCol := float64(game.Size) / 2.0
-	eval.DistanceFromCenter = calculateDistance(float64(newPos.Row), float64(newPos.Col), centerRow, centerCol)
+	// 4. Distance from center (prefer center positions); a torus has no center
+	if game.Topology != TopologyTorus {
+		eval.DistanceFromCenter = centerDistance(game, newPos)
+	}
 
 	// Calculate total score (weighted combination)
-	eval.TotalScore = calculateMoveScore(eval, game.Size)
+	eval.TotalScore = calculateMoveScore(eval, game.CenterNorm)
 
 	// Determine safety level based on multiple factors
-	eval.SafetyLevel = determineSafetyLevel(eval)
+	eval.SafetyLevel = determineSafetyLevel(eval, len(game.Directions()))
 
 	return eval
 }
 
+// RankMoves evaluates every valid move of a player and returns the
+// evaluations sorted by score, best first
+func RankMoves(game *GameState, player string, validMoves []Direction) []MoveEvaluation {
+	evaluations := make([]MoveEvaluation, 0, len(validMoves))
+	for _, dir := range validMoves {
+		eval := evaluateMove(game, getPlayerPos(game, player), dir)
+		evaluations = append(evaluations, eval)
+	}
+
+	// Sort by score (descending)
+	for i := 0; i < len(evaluations)-1; i++ {
+		for j := i + 1; j < len(evaluations); j++ {
+			if evaluations[j].TotalScore > evaluations[i].TotalScore {
+				evaluations[i], evaluations[j] = evaluations[j], evaluations[i]
+			}
+		}
+	}
+
+	return evaluations
+}
+
+// engineRank returns the 1-based position of a direction in a ranking, or 0
+// if it is not ranked
+func engineRank(evaluations []MoveEvaluation, dir Direction) int {
+	for i, eval := range evaluations {
+		if eval.Direction == dir {
+			return i + 1
+		}
+	}
+	return 0
+}
+
 // simulateMove creates a copy of game state with a move applied
 func simulateMove(game *GameState, to Position) *GameState {
 	simGame := &GameState{
-		Size:    game.Size,
-		Visited: make(map[Position]bool),
+		Width:      game.Width,
+		Height:     game.Height,
+		Topology:   game.Topology,
+		Geometry:   game.Geometry,
+		Visited:    make(map[Position]bool),
+		CenterNorm: game.CenterNorm,
 	}
 
 	// Copy visited positions
@@ -960,15 +1617,9 @@ func countReachableTerritory(game *GameState, start Position) int {
 		current := queue[0]
 		queue = queue[1:]
 
-		// Check all 4 directions
-		directions := []Position{
-			{current.Row - 1, current.Col},
-			{current.Row + 1, current.Col},
-			{current.Row, current.Col - 1},
-			{current.Row, current.Col + 1},
-		}
-
-		for _, next := range directions {
+		// Check all directions
+		for _, dir := range game.Directions() {
+			next := getNewPosition(game, current, dir)
 			if !visited[next] && IsValidMove(game, next) {
 				visited[next] = true
 				queue = append(queue, next)
@@ -1013,15 +1664,8 @@ func calculateDepthMobility(game *GameState, pos Position, maxDepth int) float64
 // getAvailablePositions returns all valid positions reachable from pos
 func getAvailablePositions(game *GameState, pos Position) []Position {
 	positions := []Position{}
-	directions := []Position{
-		{pos.Row - 1, pos.Col},
-		{pos.Row + 1, pos.Col},
-		{pos.Row, pos.Col - 1},
-		{pos.Row, pos.Col + 1},
-	}
-
-	for _, newPos := range directions {
-		if IsValidMove(game, newPos) {
+	for _, dir := range game.Directions() {
+		if newPos := getNewPosition(game, pos, dir); IsValidMove(game, newPos) {
 			positions = append(positions, newPos)
 		}
 	}
@@ -1036,8 +1680,34 @@ func calculateDistance(x1, y1, x2, y2 float64) float64 {
 	return (dx*dx + dy*dy) // Skip sqrt for performance, we only need relative comparison
 }
 
-// calculateMoveScore computes weighted score for a move
-func calculateMoveScore(eval MoveEvaluation, boardSize int) float64 {
+// centerDistance is the squared distance of a cell from the board center,
+// as the board is drawn
+func centerDistance(game *GameState, pos Position) float64 {
+	if game.Geometry == GeometryHex {
+		x, y := hexCenter(float64(pos.Row), float64(pos.Col))
+		centerX, centerY := hexCenter(float64(game.Height-1)/2.0, float64(game.Width-1)/2.0)
+		return calculateDistance(x, y, centerX, centerY)
+	}
+	centerRow := float64(game.Height) / 2.0
+	centerCol := float64(game.Width) / 2.0
+	return calculateDistance(float64(pos.Row), float64(pos.Col), centerRow, centerCol)
+}
+
+// maxCenterDistance is the cell count of the board, which square boards have
+// always normalized the center term by, or on elongated boards the larger
+// squared distance of the farthest cell (a corner) from the center, so the
+// center term never turns negative
+func maxCenterDistance(game *GameState) float64 {
+	maxDist := float64(game.Width * game.Height)
+	for _, corner := range []Position{{0, 0}, {0, game.Width - 1}, {game.Height - 1, 0}, {game.Height - 1, game.Width - 1}} {
+		maxDist = max(maxDist, centerDistance(game, corner))
+	}
+	return maxDist
+}
+
+// calculateMoveScore computes weighted score for a move. maxDist is at
+// least the largest squared distance from the center any cell has.
+func calculateMoveScore(eval MoveEvaluation, maxDist float64) float64 {
 	score := 0.0
 
 	// Territory is most important (can I control space?)
@@ -1050,33 +1720,37 @@ func calculateMoveScore(eval MoveEvaluation, boardSize int) float64 {
 	score += float64(eval.ImmediateMoves) * 5.0
 
 	// Slight preference for center positions (avoid corners/edges)
-	maxDist := float64(boardSize * boardSize)
-	centerScore := (maxDist - eval.DistanceFromCenter) / maxDist
-	score += centerScore * 1.0
+	if maxDist > 0 {
+		centerScore := (maxDist - eval.DistanceFromCenter) / maxDist
+		score += centerScore * 1.0
+	}
 
 	return score
 }
 
-// determineSafetyLevel categorizes move safety
-func determineSafetyLevel(eval MoveEvaluation) string {
+// determineSafetyLevel categorizes move safety. The thresholds on next
+// moves are for four directions and scale with the directions of the board.
+func determineSafetyLevel(eval MoveEvaluation, directions int) string {
+	moves := func(n int) int { return n * directions / 4 }
+
 	// Consider multiple factors
 	if eval.ImmediateMoves == 0 {
 		return "DEATH TRAP"
 	}
 
-	if eval.ReachableTerritory >= 20 && eval.ImmediateMoves >= 3 {
+	if eval.ReachableTerritory >= 20 && eval.ImmediateMoves >= moves(3) {
 		return "EXCELLENT"
 	}
 
-	if eval.ReachableTerritory >= 12 && eval.ImmediateMoves >= 2 {
+	if eval.ReachableTerritory >= 12 && eval.ImmediateMoves >= moves(2) {
 		return "GOOD"
 	}
 
-	if eval.ImmediateMoves >= 2 && eval.ReachableTerritory >= 6 {
+	if eval.ImmediateMoves >= moves(2) && eval.ReachableTerritory >= 6 {
 		return "MODERATE"
 	}
 
-	if eval.ImmediateMoves == 1 || eval.ReachableTerritory < 5 {
+	if eval.ImmediateMoves < moves(2) || eval.ReachableTerritory < 5 {
 		return "RISKY"
 	}
 
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestMaxCenterDistance(t *testing.T) {
+	tests := []struct {
+		width, height int
+		geometry      Geometry
+		want          float64
+	}{
+		{10, 10, GeometrySquare, 100},
+		{7, 7, GeometryHex, 49},
+		{1, 40, GeometrySquare, 400.25}, // The corner at (0,0) is 20 rows and half a column from the center
+		{40, 1, GeometrySquare, 400.25},
+	}
+	for _, tt := range tests {
+		game := InitGame(&GameSetup{
+			Width: tt.width, Height: tt.height, Geometry: tt.geometry,
+			StartPositions: []Position{{0, 0}},
+			Players:        []*PlayerConfig{{Model: "a"}},
+		})
+		if game.CenterNorm != tt.want {
+			t.Errorf("%dx%d %s board: center norm %g, want %g", tt.width, tt.height, tt.geometry, game.CenterNorm, tt.want)
+		}
+
+		// No cell may score below the farthest one
+		for row := 0; row < tt.height; row++ {
+			for col := 0; col < tt.width; col++ {
+				if d := centerDistance(game, Position{row, col}); d > game.CenterNorm {
+					t.Errorf("%dx%d %s board: (%d,%d) is %g from the center, beyond %g",
+						tt.width, tt.height, tt.geometry, row, col, d, game.CenterNorm)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MemoryMode selects whether a player remembers its earlier turns
+type MemoryMode string
+
+const (
+	MemoryOff     MemoryMode = "off"     // Stateless: every turn is a fresh prompt
+	MemoryWindow  MemoryMode = "window"  // Keep the last turns verbatim, forget older ones
+	MemorySummary MemoryMode = "summary" // Keep the last turns verbatim, summarize older ones
+)
+
+// MemoryModes lists all memory modes, the default first
+var MemoryModes = []MemoryMode{MemoryOff, MemoryWindow, MemorySummary}
+
+// summaryRequest asks a model to condense turns that leave its memory
+const summaryRequest = "Your oldest turns are about to be dropped from this conversation. " +
+	"In a few sentences, write down what you have learned about the board and your opponents, " +
+	"and the plan you intend to follow for the rest of the game. Do not choose a move now."
+
+// ParseMemoryMode validates a memory mode name
+func ParseMemoryMode(name string) (MemoryMode, error) {
+	for _, mode := range MemoryModes {
+		if MemoryMode(strings.ToLower(name)) == mode {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown memory mode %q (known: off, window, summary)", name)
+}
+
+// Memory is a player's running conversation within one game
+type Memory struct {
+	Mode    MemoryMode
+	Size    int           // Turns kept verbatim
+	Turns   []ChatMessage // Alternating prompts and replies, oldest first
+	Summary string        // Summary of the turns no longer kept (summary mode)
+}
+
+// NewMemory creates an empty memory for a player configuration, or nil
+// when the player plays stateless
+func NewMemory(config *PlayerConfig) *Memory {
+	if config.Memory == "" || config.Memory == MemoryOff {
+		return nil
+	}
+	return &Memory{Mode: config.Memory, Size: config.MemoryTurns}
+}
+
+// Messages returns the conversation to send for a new prompt
+func (m *Memory) Messages(prompt string) []ChatMessage {
+	var messages []ChatMessage
+	if m.Summary != "" {
+		messages = append(messages, ChatMessage{
+			Role:    "system",
+			Content: "Your notes from earlier turns of this game:\n" + m.Summary,
+		})
+	}
+	messages = append(messages, m.Turns...)
+	return append(messages, ChatMessage{Role: "user", Content: prompt})
+}
+
+// Remember adds a finished turn and evicts the oldest turns beyond the
+// window. In summary mode the evicted turns are first folded into the
+// summary; to limit extra calls, at least half a window is evicted at once.
+func (m *Memory) Remember(config *PlayerConfig, prompt, reply string) error {
+	m.Turns = append(m.Turns,
+		ChatMessage{Role: "user", Content: prompt},
+		ChatMessage{Role: "assistant", Content: reply})
+
+	evict := len(m.Turns)/2 - m.Size
+	if evict <= 0 {
+		return nil
+	}
+	if m.Mode == MemorySummary {
+		if evict < (m.Size+1)/2 {
+			evict = (m.Size + 1) / 2
+		}
+		if err := m.summarize(config, m.Turns[:2*evict]); err != nil {
+			return err
+		}
+	}
+	m.Turns = append([]ChatMessage(nil), m.Turns[2*evict:]...)
+	return nil
+}
+
+// summarize folds turns into the summary, asking the player's own model
+func (m *Memory) summarize(config *PlayerConfig, turns []ChatMessage) error {
+	chat, ok := backend.(ChatBackend)
+	if !ok {
+		return fmt.Errorf("the %s API does not support conversations", backend.Name())
+	}
+
+	var messages []ChatMessage
+	if m.Summary != "" {
+		messages = append(messages, ChatMessage{
+			Role:    "system",
+			Content: "Your notes from earlier turns of this game:\n" + m.Summary,
+		})
+	}
+	messages = append(messages, turns...)
+	messages = append(messages, ChatMessage{Role: "user", Content: summaryRequest})
+
+	reply, err := chat.Chat(&ChatRequest{
+		Model:       config.Model,
+		Temperature: config.Temperature,
+		Messages:    messages,
+	})
+	if err != nil {
+		return fmt.Errorf("summarizing memory: %w", err)
+	}
+
+	m.Summary = strings.TrimSpace(thinkBlockPattern.ReplaceAllString(reply.Content, ""))
+	if debugMode {
+		renderer.Debug("MEMORY SUMMARY", m.Summary)
+	}
+	return nil
+}
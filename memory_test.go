@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// summaryBackend answers every chat with a numbered summary and records the
+// requests
+type summaryBackend struct {
+	requests []*ChatRequest
+}
+
+func (b *summaryBackend) Name() string                         { return "fake" }
+func (b *summaryBackend) URL() string                          { return "" }
+func (b *summaryBackend) Supports(OutputMode) bool             { return true }
+func (b *summaryBackend) Generate(*LLMRequest) (string, error) { return "", fmt.Errorf("not used") }
+func (b *summaryBackend) Chat(req *ChatRequest) (*ChatMessage, error) {
+	b.requests = append(b.requests, req)
+	return &ChatMessage{Role: "assistant", Content: fmt.Sprintf("<think>hmm</think> summary %d ", len(b.requests))}, nil
+}
+
+// remembered lists the prompts a memory still holds verbatim
+func remembered(m *Memory) []string {
+	var prompts []string
+	for _, turn := range m.Turns {
+		if turn.Role == "user" {
+			prompts = append(prompts, turn.Content)
+		}
+	}
+	return prompts
+}
+
+func TestMemoryWindow(t *testing.T) {
+	config := &PlayerConfig{Model: "m", Memory: MemoryWindow, MemoryTurns: 2}
+	m := NewMemory(config)
+	for turn := 1; turn <= 4; turn++ {
+		if err := m.Remember(config, fmt.Sprint("prompt ", turn), fmt.Sprint("reply ", turn)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := fmt.Sprint(remembered(m)); got != "[prompt 3 prompt 4]" {
+		t.Errorf("window kept %s, want the last two turns", got)
+	}
+	if len(m.Turns) != 4 || m.Turns[3].Role != "assistant" || m.Turns[3].Content != "reply 4" {
+		t.Errorf("window turns %v, want prompts and replies alternating", m.Turns)
+	}
+	if m.Summary != "" {
+		t.Errorf("window mode wrote a summary %q", m.Summary)
+	}
+
+	messages := m.Messages("prompt 5")
+	if len(messages) != 5 || messages[0].Content != "prompt 3" || messages[4].Content != "prompt 5" {
+		t.Errorf("Messages() = %v, want the kept turns and the new prompt", messages)
+	}
+
+	if NewMemory(&PlayerConfig{Memory: MemoryOff}) != nil || NewMemory(&PlayerConfig{}) != nil {
+		t.Error("a stateless player got a memory")
+	}
+}
+
+func TestMemorySummary(t *testing.T) {
+	fake := &summaryBackend{}
+	saved := backend
+	backend = fake
+	defer func() { backend = saved }()
+
+	config := &PlayerConfig{Model: "m", Memory: MemorySummary, MemoryTurns: 3}
+	m := NewMemory(config)
+	remember := func(turn int) {
+		if err := m.Remember(config, fmt.Sprint("prompt ", turn), fmt.Sprint("reply ", turn)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for turn := 1; turn <= 3; turn++ {
+		remember(turn)
+	}
+	if len(fake.requests) != 0 {
+		t.Fatalf("summarized %d times within the window", len(fake.requests))
+	}
+
+	// One turn over the window evicts half a window at once
+	remember(4)
+	if got := fmt.Sprint(remembered(m)); got != "[prompt 3 prompt 4]" {
+		t.Errorf("summary mode kept %s, want turns 3 and 4", got)
+	}
+	if len(fake.requests) != 1 || m.Summary != "summary 1" {
+		t.Fatalf("%d summary requests, summary %q; want 1, \"summary 1\"", len(fake.requests), m.Summary)
+	}
+	request := fake.requests[0].Messages
+	if len(request) != 5 || request[0].Content != "prompt 1" || request[3].Content != "reply 2" || request[4].Content != summaryRequest {
+		t.Errorf("first summary request %v, want turns 1 and 2 and the summary request", request)
+	}
+
+	remember(5)
+	if len(fake.requests) != 1 {
+		t.Errorf("summarized again with room in the window")
+	}
+
+	// The next summary builds on the previous one
+	remember(6)
+	if got := fmt.Sprint(remembered(m)); got != "[prompt 5 prompt 6]" {
+		t.Errorf("summary mode kept %s, want turns 5 and 6", got)
+	}
+	request = fake.requests[len(fake.requests)-1].Messages
+	if len(fake.requests) != 2 || request[0].Role != "system" || request[0].Content != "Your notes from earlier turns of this game:\nsummary 1" ||
+		request[1].Content != "prompt 3" {
+		t.Errorf("second summary request %v, want the first summary and turns 3 and 4", request)
+	}
+	if messages := m.Messages("prompt 7"); messages[0].Role != "system" || messages[0].Content != "Your notes from earlier turns of this game:\nsummary 2" {
+		t.Errorf("Messages() starts with %v, want the summary", messages[0])
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetupResult aggregates the outcomes of all games played from one setup
+type SetupResult struct {
+	Number    int
+	Setup     *GameSetup
+	Games     int
+	Draws     int
+	Errors    int
+	ModelWins map[string]int
+}
+
+// NewSetupResult creates an empty result for a setup
+func NewSetupResult(number int, setup *GameSetup) *SetupResult {
+	return &SetupResult{
+		Number:    number,
+		Setup:     setup,
+		ModelWins: make(map[string]int),
+	}
+}
+
+// Record adds the outcome of one game played from a permutation of the setup
+func (r *SetupResult) Record(record *GameRecord) {
+	setup, result := record.Setup, record.Winner
+	r.Games++
+	switch result {
+	case "error":
+		r.Errors++
+	case "":
+		r.Draws++
+	default:
+		r.ModelWins[setup.Players[playerIndex(result)].Label()]++
+	}
+}
+
+// Winner returns the model that won the most games of this setup, or "" if
+// the setup was split evenly
+func (r *SetupResult) Winner() string {
+	best, bestWins, tied := "", -1, false
+	for _, model := range r.Setup.Labels() {
+		wins := r.ModelWins[model]
+		if wins > bestWins {
+			best, bestWins, tied = model, wins, false
+		} else if wins == bestWins && model != best {
+			tied = true
+		}
+	}
+	if tied || bestWins == 0 {
+		return ""
+	}
+	return best
+}
+
+// seatPermutations returns the seat orders played for each setup in mirror
+// mode. perm[i] is the original seat whose model sits in seat i. Up to
+// maxFull players every permutation is played; beyond that N! explodes (10
+// players would need 3.6M games), so the N cyclic rotations are used
+// instead, which still give every model every start position once.
+func seatPermutations(n, maxFull int) [][]int {
+	if n > maxFull {
+		rotations := make([][]int, n)
+		for shift := 0; shift < n; shift++ {
+			perm := make([]int, n)
+			for i := range perm {
+				perm[i] = (i + shift) % n
+			}
+			rotations[shift] = perm
+		}
+		return rotations
+	}
+
+	var perms [][]int
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+
+	// Heap's algorithm
+	var generate func(k int)
+	generate = func(k int) {
+		if k == 1 {
+			perms = append(perms, append([]int(nil), perm...))
+			return
+		}
+		for i := 0; i < k; i++ {
+			generate(k - 1)
+			if k%2 == 0 {
+				perm[i], perm[k-1] = perm[k-1], perm[i]
+			} else {
+				perm[0], perm[k-1] = perm[k-1], perm[0]
+			}
+		}
+	}
+	generate(n)
+
+	return perms
+}
+
+// MirrorSetup returns one setup per seat permutation. Start positions stay
+// with their seat, so models rotate through every start position and turn
+// order.
+func MirrorSetup(setup *GameSetup) []*GameSetup {
+	perms := seatPermutations(len(setup.Players), mirrorMax)
+	setups := make([]*GameSetup, 0, len(perms))
+	for _, perm := range perms {
+		players := make([]*PlayerConfig, len(perm))
+		for seat, from := range perm {
+			players[seat] = setup.Players[from]
+		}
+		setups = append(setups, &GameSetup{
+			Seed:           setup.Seed,
+			Width:          setup.Width,
+			Height:         setup.Height,
+			Topology:       setup.Topology,
+			Geometry:       setup.Geometry,
+			Arena:          setup.Arena,
+			Walls:          setup.Walls,
+			Blocked:        setup.Blocked,
+			StartPositions: setup.StartPositions,
+			Players:        players,
+		})
+	}
+	return setups
+}
+
+// DisplaySetupResult shows the aggregated outcome of one mirrored setup
+func DisplaySetupResult(r *SetupResult) {
+	fmt.Println("\n" + strings.Repeat("~", 40))
+	fmt.Printf("Setup %d results (%d games):\n", r.Number, r.Games)
+
+	positions := make([]string, len(r.Setup.StartPositions))
+	for i, pos := range r.Setup.StartPositions {
+		positions[i] = fmt.Sprintf("%s@(%d,%d)", PlayerIDs[i], pos.Row, pos.Col)
+	}
+	fmt.Printf("Start positions: %s\n", strings.Join(positions, " "))
+
+	seen := make(map[string]bool)
+	for _, model := range r.Setup.Labels() {
+		if seen[model] {
+			continue
+		}
+		seen[model] = true
+		fmt.Printf("  %s: %d wins\n", model, r.ModelWins[model])
+	}
+	if r.Draws > 0 {
+		fmt.Printf("  Draws: %d\n", r.Draws)
+	}
+	if r.Errors > 0 {
+		fmt.Printf("  Errors: %d\n", r.Errors)
+	}
+
+	if winner := r.Winner(); winner != "" {
+		fmt.Printf("Setup winner: %s\n", winner)
+	} else {
+		fmt.Println("Setup split evenly")
+	}
+	fmt.Println(strings.Repeat("~", 40))
+}
+
+// DisplaySetupSummary shows how often each model won a setup outright
+func DisplaySetupSummary(setups []*SetupResult) {
+	if len(setups) == 0 {
+		return
+	}
+
+	setupWins := make(map[string]int)
+	var models []string
+	split := 0
+	for _, r := range setups {
+		for _, model := range r.Setup.Labels() {
+			if _, ok := setupWins[model]; !ok {
+				setupWins[model] = 0
+				models = append(models, model)
+			}
+		}
+		if winner := r.Winner(); winner != "" {
+			setupWins[winner]++
+		} else {
+			split++
+		}
+	}
+
+	fmt.Printf("Setups played: %d\n", len(setups))
+	for _, model := range models {
+		fmt.Printf("  %s won %d setups\n", model, setupWins[model])
+	}
+	fmt.Printf("  Split setups: %d\n", split)
+}
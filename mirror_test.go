@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSeatPermutations(t *testing.T) {
+	tests := []struct {
+		n, maxFull int
+		want       int
+		rotations  bool
+	}{
+		{1, 4, 1, false},
+		{2, 4, 2, false},
+		{3, 4, 6, false},
+		{4, 4, 24, false},
+		{5, 4, 5, true},
+		{10, 4, 10, true},
+		{5, 5, 120, false},
+		{3, 2, 3, true},
+	}
+	for _, tt := range tests {
+		perms := seatPermutations(tt.n, tt.maxFull)
+		if len(perms) != tt.want {
+			t.Errorf("seatPermutations(%d, %d) gave %d orders, want %d", tt.n, tt.maxFull, len(perms), tt.want)
+			continue
+		}
+
+		// Every order is a distinct permutation, and every model sits in
+		// every seat equally often
+		seen := make(map[string]bool)
+		seats := make([][]int, tt.n)
+		for i := range seats {
+			seats[i] = make([]int, tt.n)
+		}
+		for _, perm := range perms {
+			key := fmt.Sprint(perm)
+			if seen[key] {
+				t.Errorf("seatPermutations(%d, %d) repeats %v", tt.n, tt.maxFull, perm)
+			}
+			seen[key] = true
+			used := make(map[int]bool)
+			for seat, from := range perm {
+				if used[from] || from < 0 || from >= tt.n {
+					t.Fatalf("seatPermutations(%d, %d): %v is no permutation", tt.n, tt.maxFull, perm)
+				}
+				used[from] = true
+				seats[seat][from]++
+			}
+			if tt.rotations {
+				for seat, from := range perm {
+					if from != (seat+perm[0])%tt.n {
+						t.Errorf("seatPermutations(%d, %d): %v is no rotation", tt.n, tt.maxFull, perm)
+						break
+					}
+				}
+			}
+		}
+		for seat := range seats {
+			for from, count := range seats[seat] {
+				if count != tt.want/tt.n {
+					t.Errorf("seatPermutations(%d, %d): seat %d holds seat %d's model %d times, want %d",
+						tt.n, tt.maxFull, seat, from, count, tt.want/tt.n)
+				}
+			}
+		}
+	}
+}
+
+func TestMirrorSetupKeepsStartPositions(t *testing.T) {
+	setup := &GameSetup{
+		Width: 5, Height: 5,
+		StartPositions: []Position{{0, 0}, {4, 4}, {2, 2}},
+		Players:        []*PlayerConfig{{Model: "a"}, {Model: "b"}, {Model: "c"}},
+	}
+	setups := MirrorSetup(setup)
+	if len(setups) != 6 {
+		t.Fatalf("%d mirrored setups for 3 players, want 6", len(setups))
+	}
+	models := make(map[string]bool)
+	for _, s := range setups {
+		if fmt.Sprint(s.StartPositions) != fmt.Sprint(setup.StartPositions) {
+			t.Errorf("start positions moved: %v", s.StartPositions)
+		}
+		models[fmt.Sprint(s.Labels())] = true
+	}
+	if len(models) != 6 {
+		t.Errorf("%d distinct seatings, want 6", len(models))
+	}
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseFailure classifies why no move could be read from a response
+type ParseFailure string
+
+const (
+	FailUnparseable ParseFailure = "unparseable" // No direction found
+	FailAmbiguous   ParseFailure = "ambiguous"   // Several directions, none of them final
+	FailIllegal     ParseFailure = "illegal"     // A clear answer that is not a legal move
+)
+
+// ParseError explains why no move could be read from a response
+type ParseError struct {
+	Failure    ParseFailure
+	Direction  Direction   // The answer that was read, if any
+	Candidates []Direction // The directions an ambiguous response names
+	Detail     string
+}
+
+func (e *ParseError) Error() string {
+	return e.Detail
+}
+
+var (
+	// directionTokenPattern matches a direction, its compass synonym or an
+	// arrow, e.g. "up", "North", "leftwards", "→", or a hex diagonal such
+	// as "up-left", "upper right", "northwest" or "↘". Only the forms with
+	// upper/top/lower/bottom may be written apart, so "down right away"
+	// stays down, and only the compass forms may be written as one word,
+	// so "downright" and "upright" are no directions.
+	directionTokenPattern = regexp.MustCompile(`(?i)\b(?:(?:up|down|north|south)[-_](?:left|right|west|east)|(?:north|south)(?:west|east)|(?:upper|top|lower|bottom)[-_ ]?(?:left|right)|up|down|left|right|north|south|east|west)(?:wards?)?\b|[↑↓←→⬆⬇⬅➡↖↗↙↘]`)
+
+	// diagonalPattern splits a hex diagonal into its vertical and
+	// horizontal part
+	diagonalPattern = regexp.MustCompile(`^(up|upper|top|down|lower|bottom|north|south)[-_ ]?(left|right|west|east)$`)
+
+	// coordinateMovePattern matches a move given as its target cell, e.g.
+	// "move to (3,4)" or "go to 3, 4"
+	coordinateMovePattern = regexp.MustCompile(`(?i)\b(?:to|move|go|head|moving|going|heading)\b[^\d(\n]{0,12}?(\(?\s*(\d+)\s*,\s*(\d+)\s*\)?)`)
+
+	// coordinatePattern matches a bare target cell, e.g. "(3,4)"
+	coordinatePattern = regexp.MustCompile(`^\(?\s*(\d+)\s*,\s*(\d+)\s*\)?`)
+
+	// letterPattern matches a one- or two-letter answer: U, D, L, R, or a
+	// hex diagonal such as UL or NW
+	letterPattern = regexp.MustCompile(`(?i)^(ul|ur|dl|dr|nw|ne|sw|se|[udlr])\b`)
+
+	// finalAnswerPattern matches a marker introducing the final answer, e.g.
+	// "MOVE: left", "**Final answer:** up" or "my choice is right". A bare
+	// "is" only counts after "my", "our" or "final", so "the worst move is
+	// up" is no answer.
+	finalAnswerPattern = regexp.MustCompile("(?i)(?:\\b(?:final answer|answer|move|direction|decision|choice|action)\\b[*_\\s]*[:=]|" +
+		"\\b(?:(?:my|our)\\s+(?:final\\s+)?|final\\s+)(?:answer|move|direction|decision|choice|action)[*_\\s]*\\bis\\b)[*_\\s\"'`:]*")
+
+	// clauseBoundaryPattern separates the clauses a negation applies to
+	clauseBoundaryPattern = regexp.MustCompile(`(?i)[.;,:!?\n]|\b(?:but|so|therefore|thus|hence|because|since|whereas|while)\b`)
+
+	// negationPattern rules out the directions that follow it in a clause,
+	// e.g. "I won't go up" or "avoid left"
+	negationPattern = regexp.MustCompile(`(?i)n[’']t\b|\b(?:not|never|neither|nor|avoid|avoiding|cannot|instead of|rather than|except)\b`)
+
+	// verdictPattern rules out the direction before it, e.g. "up is blocked"
+	verdictPattern = regexp.MustCompile(`(?i)n[’']t\b|\b(?:not|never|blocked|trap|dead end|deadly|fatal|suicid\w*|lose|loses|illegal|invalid|impossible|out of bounds)\b`)
+
+	// listJoinPattern matches the text between directions listed together,
+	// e.g. "up or left" and "up, left"
+	listJoinPattern = regexp.MustCompile(`(?i)^[\s,/&|]*(?:\b(?:and|or|nor)\b)?[\s,/&|]*$`)
+)
+
+// directionSynonyms maps every recognized direction token to its direction
+var directionSynonyms = map[string]Direction{
+	"up": Up, "north": Up, "↑": Up, "⬆": Up, "u": Up,
+	"down": Down, "south": Down, "↓": Down, "⬇": Down, "d": Down,
+	"left": Left, "west": Left, "←": Left, "⬅": Left, "l": Left,
+	"right": Right, "east": Right, "→": Right, "➡": Right, "r": Right,
+	"up-left": UpLeft, "↖": UpLeft, "ul": UpLeft, "nw": UpLeft,
+	"up-right": UpRight, "↗": UpRight, "ur": UpRight, "ne": UpRight,
+	"down-left": DownLeft, "↙": DownLeft, "dl": DownLeft, "sw": DownLeft,
+	"down-right": DownRight, "↘": DownRight, "dr": DownRight, "se": DownRight,
+}
+
+// synonymDirection looks up a direction token, ignoring case and a
+// "-ward(s)" suffix. A diagonal is normalized from its parts, so "Upper
+// Left", "up_left" and "northwest" all read as up-left.
+func synonymDirection(token string) (Direction, bool) {
+	token = strings.ToLower(token)
+	token = strings.TrimSuffix(strings.TrimSuffix(token, "s"), "ward")
+	if parts := diagonalPattern.FindStringSubmatch(token); parts != nil {
+		vertical, _ := synonymDirection(strings.NewReplacer("upper", "up", "top", "up", "lower", "down", "bottom", "down").Replace(parts[1]))
+		horizontal, _ := synonymDirection(parts[2])
+		token = string(vertical) + "-" + string(horizontal)
+	}
+	dir, ok := directionSynonyms[token]
+	return dir, ok
+}
+
+// coordinateDirection reads a target cell such as "(3,4)" and returns the
+// direction leading to it, if it is one of the targets of this move
+func coordinateDirection(row, col string, targets map[Position]Direction) (Direction, bool) {
+	r, err := strconv.Atoi(row)
+	if err != nil {
+		return "", false
+	}
+	c, err := strconv.Atoi(col)
+	if err != nil {
+		return "", false
+	}
+	dir, ok := targets[Position{r, c}]
+	return dir, ok
+}
+
+// mention is one direction named in a response
+type mention struct {
+	Direction Direction
+	Start     int
+	End       int
+	Clause    int  // Mentions in the same clause or list share a number
+	Negated   bool // Ruled out, e.g. "not up" or "up is blocked"
+}
+
+// findMentions lists the directions a text names, in order, and works out
+// which of them it rules out
+func findMentions(text string, targets map[Position]Direction) []mention {
+	var mentions []mention
+	for _, loc := range directionTokenPattern.FindAllStringIndex(text, -1) {
+		if dir, ok := synonymDirection(text[loc[0]:loc[1]]); ok {
+			mentions = append(mentions, mention{Direction: dir, Start: loc[0], End: loc[1]})
+		}
+	}
+	for _, loc := range coordinateMovePattern.FindAllStringSubmatchIndex(text, -1) {
+		if dir, ok := coordinateDirection(text[loc[4]:loc[5]], text[loc[6]:loc[7]], targets); ok {
+			mentions = append(mentions, mention{Direction: dir, Start: loc[2], End: loc[3]})
+		}
+	}
+	sort.Slice(mentions, func(i, j int) bool { return mentions[i].Start < mentions[j].Start })
+	for i := 1; i < len(mentions); i++ {
+		if mentions[i].Start < mentions[i-1].End {
+			mentions = append(mentions[:i], mentions[i+1:]...)
+			i--
+		}
+	}
+
+	boundaries := clauseBoundaryPattern.FindAllStringIndex(text, -1)
+	clauseOf := func(pos int) int {
+		return sort.Search(len(boundaries), func(i int) bool { return boundaries[i][0] >= pos })
+	}
+	clauseStart := func(clause int) int {
+		if clause == 0 {
+			return 0
+		}
+		return boundaries[clause-1][1]
+	}
+	clauseEnd := func(clause int) int {
+		if clause == len(boundaries) {
+			return len(text)
+		}
+		return boundaries[clause][0]
+	}
+
+	for i := range mentions {
+		m := &mentions[i]
+		m.Clause = clauseOf(m.Start)
+		start := clauseStart(m.Clause)
+
+		if i > 0 {
+			prev := mentions[i-1]
+			if listJoinPattern.MatchString(text[prev.End:m.Start]) {
+				// Listed together: "not up or down", "up, left"
+				m.Clause = prev.Clause
+				m.Negated = prev.Negated
+				continue
+			}
+			if prev.Clause == m.Clause {
+				start = prev.End
+			}
+		}
+
+		// A target cell such as "(3,4)" spans a comma
+		end := clauseEnd(clauseOf(m.End))
+		if i+1 < len(mentions) && mentions[i+1].Start < end {
+			end = mentions[i+1].Start
+		}
+		m.Negated = negationPattern.MatchString(text[start:m.Start]) || verdictPattern.MatchString(text[m.End:end])
+	}
+	return mentions
+}
+
+// leadingDirection reads a direction at the very start of a text
+func leadingDirection(text string, targets map[Position]Direction) (Direction, bool) {
+	if loc := directionTokenPattern.FindStringIndex(text); loc != nil && loc[0] == 0 {
+		return synonymDirection(text[:loc[1]])
+	}
+	if match := letterPattern.FindStringSubmatch(text); match != nil {
+		return synonymDirection(match[1])
+	}
+	if match := coordinatePattern.FindStringSubmatch(text); match != nil {
+		return coordinateDirection(match[1], match[2], targets)
+	}
+	return "", false
+}
+
+// singleToken reads an answer that is nothing but a direction, e.g. "Left.",
+// "R", "NW", "⬅️" or "(3,4)"
+func singleToken(answer string, targets map[Position]Direction) (Direction, bool) {
+	token := strings.Trim(strings.ReplaceAll(answer, "\ufe0f", ""), " \t\r\n.!*_\"'`")
+	dir, ok := leadingDirection(token, targets)
+	if !ok {
+		return "", false
+	}
+	// The direction has to be the whole answer
+	if loc := directionTokenPattern.FindStringIndex(token); loc != nil && loc[0] == 0 && loc[1] == len(token) {
+		return dir, true
+	}
+	if match := letterPattern.FindString(token); match == token {
+		return dir, true
+	}
+	if match := coordinatePattern.FindString(token); match == token {
+		return dir, true
+	}
+	return "", false
+}
+
+// finalAnswer finds the last final-answer marker followed by a direction,
+// and returns the direction and where the marker starts
+func finalAnswer(text string, targets map[Position]Direction) (Direction, int, bool) {
+	markers := finalAnswerPattern.FindAllStringIndex(text, -1)
+	for i := len(markers) - 1; i >= 0; i-- {
+		if dir, ok := leadingDirection(text[markers[i][1]:], targets); ok {
+			return dir, markers[i][0], true
+		}
+	}
+	return "", 0, false
+}
+
+// lastMention settles on the direction of the last clause that names one
+// without ruling it out. A clause naming several, as in "up or left", is
+// ambiguous.
+func lastMention(text string, targets map[Position]Direction) (Direction, error) {
+	mentions := findMentions(text, targets)
+	var chosen []mention
+	for _, m := range mentions {
+		if !m.Negated {
+			chosen = append(chosen, m)
+		}
+	}
+	if len(chosen) == 0 {
+		if len(mentions) > 0 {
+			return "", &ParseError{Failure: FailUnparseable, Detail: "every direction in the response is ruled out"}
+		}
+		return "", &ParseError{Failure: FailUnparseable, Detail: "could not parse direction from response"}
+	}
+
+	last := chosen[len(chosen)-1]
+	seen := make(map[Direction]bool)
+	var candidates []Direction
+	var names []string
+	for _, m := range chosen {
+		if m.Clause == last.Clause && !seen[m.Direction] {
+			seen[m.Direction] = true
+			candidates = append(candidates, m.Direction)
+			names = append(names, string(m.Direction))
+		}
+	}
+	if len(candidates) > 1 {
+		return "", &ParseError{
+			Failure:    FailAmbiguous,
+			Candidates: candidates,
+			Detail:     fmt.Sprintf("ambiguous response: names %s", strings.Join(names, " and ")),
+		}
+	}
+	return last.Direction, nil
+}
+
+// isAmbiguous reports whether a response was rejected for naming several
+// directions
+func isAmbiguous(err error) bool {
+	var parseErr *ParseError
+	return errors.As(err, &parseErr) && parseErr.Failure == FailAmbiguous
+}
+
+// checkMove verifies that a parsed direction is a legal move
+func checkMove(dir Direction, validMoves []Direction) error {
+	for _, validDir := range validMoves {
+		if dir == validDir {
+			return nil
+		}
+	}
+	return &ParseError{Failure: FailIllegal, Direction: dir, Detail: fmt.Sprintf("direction '%s' is not valid", dir)}
+}
+
+// ParseDirection extracts and validates a direction from the LLM response.
+// A bare direction wins, then the last final-answer marker ("Move: left"),
+// then the last clause naming a direction it does not rule out, so "I won't
+// go up, I'll go left" is read as left. Compass directions, arrows, the
+// letters U/D/L/R, hex diagonals such as "up-left" or NW and target cells
+// such as "move to (3,4)" are understood.
+func ParseDirection(response string, targets map[Position]Direction, validMoves []Direction) (Direction, error) {
+	answer := strings.TrimSpace(thinkBlockPattern.ReplaceAllString(response, ""))
+	if answer == "" {
+		answer = response
+	}
+
+	dir, ok := singleToken(answer, targets)
+	if !ok {
+		dir, _, ok = finalAnswer(answer, targets)
+	}
+	if !ok {
+		var err error
+		if dir, err = lastMention(answer, targets); err != nil {
+			return "", err
+		}
+	}
+	if err := checkMove(dir, validMoves); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import "testing"
+
+func TestParseDirection(t *testing.T) {
+	// The player is at (5,5) on a square board
+	targets := map[Position]Direction{
+		{4, 5}: Up,
+		{6, 5}: Down,
+		{5, 4}: Left,
+		{5, 6}: Right,
+	}
+	valid := []Direction{Up, Down, Left, Right}
+
+	tests := []struct {
+		response string
+		want     Direction
+	}{
+		{"left", Left},
+		{"Left.", Left},
+		{"**LEFT**", Left},
+		{"L", Left},
+		{"←", Left},
+		{"(5,4)", Left},
+		{"I won't go up, I'll go left", Left},
+		{"Up is a death trap, so left", Left},
+		{"Move: left", Left},
+		{"**Final answer:** L", Left},
+		{"my choice is west", Left},
+		{"My final answer is down.", Down},
+		{"The final move is right", Right},
+		{"The worst move is up, so I'll go left.", Left},
+		{"The best direction is unclear. I'll go down.", Down},
+		{"Up looks open but I'd rather go north... actually, Move: down", Down},
+		{"I'll move to (6,5)", Down},
+		{"Going north", Up},
+		{"<think>up is blocked, maybe right</think>\nright", Right},
+		{"Avoid left and go east", Right},
+		{"That is downright dangerous, so up", Up},
+	}
+	for _, tt := range tests {
+		got, err := ParseDirection(tt.response, targets, valid)
+		if err != nil {
+			t.Errorf("ParseDirection(%q): %v", tt.response, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDirection(%q) = %s, want %s", tt.response, got, tt.want)
+		}
+	}
+}
+
+func TestParseDirectionFailures(t *testing.T) {
+	targets := map[Position]Direction{{4, 5}: Up, {6, 5}: Down, {5, 4}: Left, {5, 6}: Right}
+	valid := []Direction{Up, Down, Left}
+
+	tests := []struct {
+		response string
+		failure  ParseFailure
+	}{
+		{"I think up or left", FailAmbiguous},
+		{"up, left", FailAmbiguous},
+		{"right", FailIllegal},
+		{"Move: right", FailIllegal},
+		{"I have no idea", FailUnparseable},
+		{"not up, never left", FailUnparseable},
+		{"Standing upright", FailUnparseable},
+	}
+	for _, tt := range tests {
+		_, err := ParseDirection(tt.response, targets, valid)
+		parseErr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("ParseDirection(%q) = %v, want a %s ParseError", tt.response, err, tt.failure)
+			continue
+		}
+		if parseErr.Failure != tt.failure {
+			t.Errorf("ParseDirection(%q) failed as %s, want %s", tt.response, parseErr.Failure, tt.failure)
+		}
+	}
+}
+
+func TestParseHexDirection(t *testing.T) {
+	// The player is at (3,3) on a hex board
+	targets := map[Position]Direction{
+		{2, 3}: UpLeft,
+		{2, 4}: UpRight,
+		{3, 2}: Left,
+		{3, 4}: Right,
+		{4, 2}: DownLeft,
+		{4, 3}: DownRight,
+	}
+
+	tests := []struct {
+		response string
+		want     Direction
+	}{
+		{"up-left", UpLeft},
+		{"Upper Right", UpRight},
+		{"up_left", UpLeft},
+		{"northwest", UpLeft},
+		{"South-East", DownRight},
+		{"NE", UpRight},
+		{"DL", DownLeft},
+		{"↘", DownRight},
+		{"Move: down-left", DownLeft},
+		{"I won't go up-right, I'll go left", Left},
+		{"move to (4,3)", DownRight},
+	}
+	for _, tt := range tests {
+		got, err := ParseDirection(tt.response, targets, HexDirections)
+		if err != nil {
+			t.Errorf("ParseDirection(%q): %v", tt.response, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDirection(%q) = %s, want %s", tt.response, got, tt.want)
+		}
+	}
+}
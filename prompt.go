@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// PromptSection names one part of the prompt that can be switched off per
+// player, so ablation runs can test what actually helps a model
+type PromptSection string
+
+const (
+	SectionRules     PromptSection = "rules"     // Game rules
+	SectionHistory   PromptSection = "history"   // Recent move history
+	SectionPositions PromptSection = "positions" // Current player positions
+	SectionBoard     PromptSection = "board"     // Board drawing
+	SectionAnalysis  PromptSection = "analysis"  // Ranked move analysis with scores
+	SectionBlocked   PromptSection = "blocked"   // Blocked moves and why
+	SectionStrategy  PromptSection = "strategy"  // Strategy guide explaining the analysis
+	SectionFormat    PromptSection = "format"    // Response format instructions
+)
+
+// PromptSections lists all sections in prompt order
+var PromptSections = []PromptSection{
+	SectionRules, SectionHistory, SectionPositions, SectionBoard,
+	SectionAnalysis, SectionBlocked, SectionStrategy, SectionFormat,
+}
+
+// ParseSections parses a section list such as "all,-strategy" or
+// "rules,board,format". "all" enables every section and a leading "-"
+// disables one. It returns the enabled set and its canonical spelling, which
+// is "" when every section is enabled.
+func ParseSections(spec string) (map[PromptSection]bool, string, error) {
+	enabled := make(map[PromptSection]bool)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(strings.ToLower(item))
+		if item == "" {
+			continue
+		}
+		if item == "all" {
+			for _, section := range PromptSections {
+				enabled[section] = true
+			}
+			continue
+		}
+
+		on := true
+		if strings.HasPrefix(item, "-") {
+			on = false
+			item = item[1:]
+		}
+		section := PromptSection(item)
+		if !isPromptSection(section) {
+			return nil, "", fmt.Errorf("unknown prompt section %q (known: %s)", item, sectionNames(PromptSections))
+		}
+		if on {
+			enabled[section] = true
+		} else {
+			delete(enabled, section)
+		}
+	}
+
+	if len(enabled) == len(PromptSections) {
+		return enabled, "", nil
+	}
+
+	var names []PromptSection
+	for _, section := range PromptSections {
+		if enabled[section] {
+			names = append(names, section)
+		}
+	}
+	return enabled, sectionNames(names), nil
+}
+
+func isPromptSection(section PromptSection) bool {
+	for _, known := range PromptSections {
+		if section == known {
+			return true
+		}
+	}
+	return false
+}
+
+func sectionNames(sections []PromptSection) string {
+	names := make([]string, len(sections))
+	for i, section := range sections {
+		names[i] = string(section)
+	}
+	return strings.Join(names, ",")
+}
+
+// historyLength is the number of recent moves included in the prompt
+const historyLength = 20
+
+//go:embed prompts/default.tmpl
+var defaultPromptSource string
+
+// defaultPrompt is the built-in prompt template
+var defaultPrompt = mustParsePrompt("default", defaultPromptSource)
+
+// promptFuncs are the functions available to prompt templates in addition to
+// the text/template builtins
+var promptFuncs = template.FuncMap{
+	"upper":      func(v any) string { return strings.ToUpper(fmt.Sprint(v)) },
+	"lower":      func(v any) string { return strings.ToLower(fmt.Sprint(v)) },
+	"directions": formatValidMoves,
+}
+
+// PromptTemplate is a parsed prompt template. Its version is a hash of the
+// template source, so any edit to the wording yields a new version.
+type PromptTemplate struct {
+	Name    string
+	Version string
+	tmpl    *template.Template
+}
+
+// ParsePrompt parses a prompt template from its source
+func ParsePrompt(name, source string) (*PromptTemplate, error) {
+	tmpl, err := template.New(name).Funcs(promptFuncs).Option("missingkey=zero").Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(source))
+	return &PromptTemplate{
+		Name:    name,
+		Version: hex.EncodeToString(sum[:6]),
+		tmpl:    tmpl,
+	}, nil
+}
+
+func mustParsePrompt(name, source string) *PromptTemplate {
+	prompt, err := ParsePrompt(name, source)
+	if err != nil {
+		panic(err)
+	}
+	return prompt
+}
+
+// LoadPrompt reads and parses a prompt template file
+func LoadPrompt(path string) (*PromptTemplate, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePrompt(filepath.Base(path), string(source))
+}
+
+// PromptData is the data model prompt templates are rendered against
+type PromptData struct {
+	Player       string          // Your player ID
+	NumPlayers   int             // Players at the start of the game
+	Width        int             // Board columns
+	Height       int             // Board rows
+	MaxRow       int             // Bottom row, Height-1
+	MaxCol       int             // Rightmost column, Width-1
+	Walls        bool            // The board has walls (#)
+	BlockedCells bool            // The board has pre-blocked cells (x)
+	Torus        bool            // Moving off one edge enters the opposite edge
+	Hex          bool            // The board is made of hexagons
+	Directions   []Direction     // Every direction of the board, legal or not
+	Position     Position        // Your position
+	Players      []PromptPlayer  // Every player in seat order, including you
+	Board        string          // Board drawing in the player's encoding
+	Encoding     BoardEncoding   // How Board is drawn
+	ValidMoves   []Direction     // Legal directions
+	Evaluations  []PromptMove    // Legal moves ranked by the engine, best first
+	Blocked      []BlockedMove   // Illegal directions and why
+	History      []HistoryMove   // The most recent moves, oldest first
+	Sections     map[string]bool // Enabled prompt sections, by name
+	Reasoning    bool            // Ask for reasoning and a final MOVE line instead of one word
+	Output       OutputMode      // How the answer is constrained: text, json or grammar
+	Agent        bool            // Answer by calling tools, at most MaxToolCalls per turn
+	MaxToolCalls int
+	Memory       bool            // Earlier turns of this game precede the prompt in the conversation
+	Examples     []PromptExample // Worked example positions, empty unless an example library is set
+}
+
+// PromptPlayer describes one player in PromptData
+type PromptPlayer struct {
+	ID       string
+	Position Position
+	Active   bool // Still in the game
+	You      bool // The player the prompt is for
+}
+
+// PromptMove is one ranked legal move in PromptData
+type PromptMove struct {
+	MoveEvaluation
+	Rank  int  // 1 for the best move
+	Top   bool // Best-ranked move
+	Worst bool // Worst-ranked move, when there is more than one
+}
+
+// BlockedMove is an illegal direction in PromptData
+type BlockedMove struct {
+	Direction Direction
+	Reason    string
+}
+
+// HistoryMove is one past move in PromptData
+type HistoryMove struct {
+	Number int // Move number in the game, starting at 1
+	Move
+}
+
+// PromptExample is one worked example position in PromptData
+type PromptExample struct {
+	Number   int // Starting at 1
+	Name     string
+	Player   string   // The player to move in the example, "you" in its board
+	Position Position // That player's position
+	Board    string   // Board drawing in the same encoding as Board
+	Move     Direction
+	Note     string // Why the move is correct, may be empty
+}
+
+// NewPromptData collects everything a prompt template can show a player
+func NewPromptData(game *GameState, player string, validMoves []Direction) *PromptData {
+	data := &PromptData{
+		Player:     player,
+		NumPlayers: game.NumPlayers,
+		Width:      game.Width,
+		Height:     game.Height,
+		MaxRow:     game.Height - 1,
+		MaxCol:     game.Width - 1,
+		Position:   game.PlayerPos[player],
+		ValidMoves: validMoves,
+		Sections:   make(map[string]bool),
+	}
+	data.Walls = len(gridCells(game, Wall)) > 0
+	data.BlockedCells = len(gridCells(game, Blocked)) > 0
+	data.Torus = game.Topology == TopologyTorus
+	data.Hex = game.Geometry == GeometryHex
+	data.Directions = game.Directions()
+
+	for i := 0; i < game.NumPlayers; i++ {
+		id := PlayerIDs[i]
+		data.Players = append(data.Players, PromptPlayer{
+			ID:       id,
+			Position: game.PlayerPos[id],
+			Active:   game.ActivePlayers[id],
+			You:      id == player,
+		})
+	}
+
+	evaluations := RankMoves(game, player, validMoves)
+	for i, eval := range evaluations {
+		data.Evaluations = append(data.Evaluations, PromptMove{
+			MoveEvaluation: eval,
+			Rank:           i + 1,
+			Top:            i == 0,
+			Worst:          i == len(evaluations)-1 && len(evaluations) > 1,
+		})
+	}
+
+	blocked := getBlockedMoves(game, player, validMoves)
+	for _, dir := range game.Directions() {
+		if reason, ok := blocked[dir]; ok {
+			data.Blocked = append(data.Blocked, BlockedMove{Direction: dir, Reason: reason})
+		}
+	}
+
+	start := len(game.Moves) - historyLength
+	if start < 0 {
+		start = 0
+	}
+	for i := start; i < len(game.Moves); i++ {
+		data.History = append(data.History, HistoryMove{Number: i + 1, Move: game.Moves[i]})
+	}
+
+	data.Reasoning = game.PlayerConfigs[player].Reasoning
+	data.Memory = game.Memories[player] != nil
+	data.Agent = game.PlayerConfigs[player].Agent
+	data.MaxToolCalls = maxToolCalls
+	data.Output = game.PlayerConfigs[player].Output
+	if data.Output == "" {
+		data.Output = OutputText
+	}
+	data.Encoding = game.PlayerConfigs[player].Board
+	if data.Encoding == "" {
+		data.Encoding = EncodingGrid
+	}
+	data.Board = FormatBoard(game, player, data.Encoding)
+
+	if config := game.PlayerConfigs[player]; config.Examples != nil {
+		for i, example := range config.Examples.Select(game, player, config.ExampleCount, config.ExampleSelect) {
+			data.Examples = append(data.Examples, PromptExample{
+				Number:   i + 1,
+				Name:     example.Name,
+				Player:   example.Player,
+				Position: example.game.PlayerPos[example.Player],
+				Board:    FormatBoard(example.game, example.Player, data.Encoding),
+				Move:     example.Move,
+				Note:     example.Note,
+			})
+		}
+	}
+
+	sections := game.PlayerConfigs[player].Sections
+	for _, section := range PromptSections {
+		data.Sections[string(section)] = sections == nil || sections[section]
+	}
+
+	return data
+}
+
+// BuildPrompt renders the player's prompt template, or the default one
+func BuildPrompt(game *GameState, player string, validMoves []Direction) (string, error) {
+	prompt := game.PlayerConfigs[player].Prompt
+	if prompt == nil {
+		prompt = defaultPrompt
+	}
+
+	var buf bytes.Buffer
+	if err := prompt.tmpl.Execute(&buf, NewPromptData(game, player, validMoves)); err != nil {
+		return "", fmt.Errorf("prompt template %s: %w", prompt.Name, err)
+	}
+	return buf.String(), nil
+}
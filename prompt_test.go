@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSections(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string // Canonical spelling, "" for every section
+		enabled []PromptSection
+	}{
+		{"all", "", PromptSections},
+		{"all,-strategy", "rules,history,positions,board,analysis,blocked,format",
+			[]PromptSection{SectionRules, SectionHistory, SectionPositions, SectionBoard, SectionAnalysis, SectionBlocked, SectionFormat}},
+		{"format, Board ,RULES", "rules,board,format", []PromptSection{SectionRules, SectionBoard, SectionFormat}},
+		{"rules,board,-board", "rules", []PromptSection{SectionRules}},
+		{"-analysis,all", "", PromptSections},
+		{"rules,history,positions,board,analysis,blocked,strategy,format", "", PromptSections},
+	}
+	for _, tt := range tests {
+		enabled, canonical, err := ParseSections(tt.spec)
+		if err != nil {
+			t.Errorf("ParseSections(%q): %v", tt.spec, err)
+			continue
+		}
+		if canonical != tt.want {
+			t.Errorf("ParseSections(%q) spelled %q, want %q", tt.spec, canonical, tt.want)
+		}
+		if len(enabled) != len(tt.enabled) {
+			t.Errorf("ParseSections(%q) enabled %d sections, want %d", tt.spec, len(enabled), len(tt.enabled))
+		}
+		for _, section := range tt.enabled {
+			if !enabled[section] {
+				t.Errorf("ParseSections(%q) left %s off", tt.spec, section)
+			}
+		}
+	}
+
+	for _, spec := range []string{"rules,tips", "-colors", "all,-"} {
+		_, _, err := ParseSections(spec)
+		if err == nil || !strings.Contains(err.Error(), "unknown prompt section") {
+			t.Errorf("ParseSections(%q) = %v, want an unknown section error", spec, err)
+		}
+	}
+}
+
+func TestPromptVersion(t *testing.T) {
+	// The version is a hash of the source alone, so stored runs keep
+	// matching their prompt across releases and file names
+	source := "Pick a direction: {{directions .ValidMoves}}"
+	prompt, err := ParsePrompt("a.tmpl", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prompt.Version != "23606e56ebb4" {
+		t.Errorf("version %s, want 23606e56ebb4", prompt.Version)
+	}
+
+	path := filepath.Join(t.TempDir(), "b.tmpl")
+	if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadPrompt(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Version != prompt.Version || loaded.Name != "b.tmpl" {
+		t.Errorf("loaded %s version %s, want b.tmpl version %s", loaded.Name, loaded.Version, prompt.Version)
+	}
+
+	edited, err := ParsePrompt("a.tmpl", source+"\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.Version == prompt.Version {
+		t.Errorf("an edited template kept version %s", edited.Version)
+	}
+
+	builtin, err := LoadPrompt("prompts/default.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builtin.Version != defaultPrompt.Version {
+		t.Errorf("prompts/default.tmpl has version %s, the embedded default %s", builtin.Version, defaultPrompt.Version)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// thinkBlockPattern matches the <think> blocks reasoning models wrap their
+// chain of thought in; an unclosed block runs to the end
+var thinkBlockPattern = regexp.MustCompile(`(?is)<think>(.*?)(?:</think>|$)`)
+
+// ParseReasonedMove extracts the move from a free-form response that ends
+// with a "MOVE: <direction>" line, and returns the reasoning that led to it.
+// The last final-answer marker wins, preferably outside <think> blocks;
+// without one, the direction the answer settles on is used (see
+// ParseDirection).
+func ParseReasonedMove(response string, targets map[Position]Direction, validMoves []Direction) (Direction, string, error) {
+	var thoughts []string
+	for _, match := range thinkBlockPattern.FindAllStringSubmatch(response, -1) {
+		if thought := strings.TrimSpace(match[1]); thought != "" {
+			thoughts = append(thoughts, thought)
+		}
+	}
+	answer := strings.TrimSpace(thinkBlockPattern.ReplaceAllString(response, ""))
+
+	reasoning := func(before string) string {
+		// Drop markdown emphasis left over from "**MOVE:** up"
+		if before = strings.TrimRight(before, " \t\r\n*_#>"); before != "" {
+			return strings.Join(append(thoughts, before), "\n\n")
+		}
+		return strings.Join(thoughts, "\n\n")
+	}
+	settle := func(dir Direction, thought string) (Direction, string, error) {
+		if err := checkMove(dir, validMoves); err != nil {
+			return "", reasoning(answer), err
+		}
+		return dir, thought, nil
+	}
+
+	// An explicit final answer, preferably outside the thinking
+	for _, text := range []string{answer, response} {
+		dir, at, ok := finalAnswer(text, targets)
+		if !ok {
+			continue
+		}
+		if text == answer {
+			return settle(dir, reasoning(answer[:at]))
+		}
+		return settle(dir, reasoning(answer))
+	}
+
+	// A bare one-word answer carries no reasoning of its own
+	if dir, ok := singleToken(answer, targets); ok {
+		return settle(dir, reasoning(""))
+	}
+
+	// Otherwise the direction the answer settles on
+	dir, err := lastMention(answer, targets)
+	if err != nil {
+		return "", reasoning(answer), err
+	}
+	return settle(dir, reasoning(answer))
+}
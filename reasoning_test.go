@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestParseReasonedMove(t *testing.T) {
+	targets := map[Position]Direction{{4, 5}: Up, {6, 5}: Down, {5, 4}: Left, {5, 6}: Right}
+	valid := []Direction{Up, Down, Left, Right}
+
+	tests := []struct {
+		response  string
+		want      Direction
+		reasoning string
+	}{
+		{"Up is open and leads to the center.\nMOVE: up", Up, "Up is open and leads to the center."},
+		{"Left looks good.\nMOVE: left\nOn second thought, right has more room.\nMOVE: right", Right,
+			"Left looks good.\nMOVE: left\nOn second thought, right has more room."},
+		{"Down has the most space.\n**MOVE:** down", Down, "Down has the most space."},
+		{"<think>Up is a wall. Left is a trail. MOVE: up?</think>\nMOVE: down", Down,
+			"Up is a wall. Left is a trail. MOVE: up?"},
+		{"<think>Only right is safe.\nMOVE: right</think>", Right, "Only right is safe.\nMOVE: right"},
+		{"<think>First thought</think>Then I'll settle.\n<think>Second thought</think>\nMOVE: left", Left,
+			"First thought\n\nSecond thought\n\nThen I'll settle."},
+		{"<think>\nPondering...\n</think>\n\nright", Right, "Pondering..."},
+		{"Up is a trap, so I'll go down.", Down, "Up is a trap, so I'll go down."},
+		{"MOVE: (5,4)", Left, ""},
+	}
+	for _, tt := range tests {
+		got, reasoning, err := ParseReasonedMove(tt.response, targets, valid)
+		if err != nil {
+			t.Errorf("ParseReasonedMove(%q): %v", tt.response, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseReasonedMove(%q) = %s, want %s", tt.response, got, tt.want)
+		}
+		if reasoning != tt.reasoning {
+			t.Errorf("ParseReasonedMove(%q) reasoning %q, want %q", tt.response, reasoning, tt.reasoning)
+		}
+	}
+
+	// An illegal final answer fails, but keeps the reasoning for the retry log
+	_, reasoning, err := ParseReasonedMove("Right is best.\nMOVE: right", targets, []Direction{Up, Down})
+	if parseErr, ok := err.(*ParseError); !ok || parseErr.Failure != FailIllegal {
+		t.Errorf("illegal final answer: %v, want an illegal move error", err)
+	}
+	if reasoning != "Right is best.\nMOVE: right" {
+		t.Errorf("illegal final answer kept reasoning %q", reasoning)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Verbosity selects how much of each game is printed
+type Verbosity int
+
+const (
+	VerbositySilent  Verbosity = iota // Only the final statistics
+	VerbositySummary                  // One line per game
+	VerbosityMoves                    // One line per move
+	VerbosityBoard                    // The full board after every move
+)
+
+// verbosityNames names the verbosity levels, from least to most output
+var verbosityNames = []string{"silent", "summary", "moves", "board"}
+
+// ParseVerbosity validates a verbosity level name
+func ParseVerbosity(name string) (Verbosity, error) {
+	for i, level := range verbosityNames {
+		if strings.ToLower(name) == level {
+			return Verbosity(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown verbosity %q (known: %s)", name, strings.Join(verbosityNames, ", "))
+}
+
+// Renderer presents games while they are played. PlayGame and the move
+// functions report every event through it instead of printing.
+type Renderer interface {
+	GameStarted(game *GameState, number int)
+	Thinking(game *GameState, player string, turn int)
+	Prompted(game *GameState, player string, prompt string)
+	Rejected(game *GameState, player string, attempt Attempt, retry int)
+	Moved(game *GameState, move *Move)
+	Eliminated(game *GameState, player string, turn int)
+	GameOver(game *GameState, record *GameRecord)
+	Stats(stats *GameStats)          // Statistics so far, after each game
+	SetupResult(result *SetupResult) // Outcome of a mirrored setup, after its last game
+	Warning(text string)             // Problems outside the games, e.g. a failed save
+	Debug(title, text string)        // Diagnostics shown with -debug; a title frames a block
+}
+
+// textRenderer prints games as scrolling text at a verbosity level
+type textRenderer struct {
+	level Verbosity
+}
+
+// NewTextRenderer creates the renderer for normal terminal output
+func NewTextRenderer(level Verbosity) Renderer {
+	return &textRenderer{level: level}
+}
+
+func (r *textRenderer) GameStarted(game *GameState, number int) {
+	if r.level < VerbosityMoves {
+		return
+	}
+	fmt.Printf("\n========== Game %d ==========\n", number)
+	fmt.Printf("\nGame %d. Starting positions:\n", number)
+	for i := 0; i < game.NumPlayers; i++ {
+		playerID := PlayerIDs[i]
+		pos := game.PlayerPos[playerID]
+		fmt.Printf("Player %s: (%d, %d) [%s]\n", playerID, pos.Row, pos.Col, game.PlayerConfigs[playerID].Label())
+	}
+	fmt.Println()
+	if r.level == VerbosityBoard {
+		DisplayBoard(game)
+	}
+}
+
+func (r *textRenderer) Thinking(game *GameState, player string, turn int) {
+	if r.level == VerbosityBoard {
+		fmt.Printf("\n--- Move %d: Player %s's turn ---\n", turn, player)
+	}
+}
+
+func (r *textRenderer) Prompted(game *GameState, player string, prompt string) {}
+
+func (r *textRenderer) Rejected(game *GameState, player string, attempt Attempt, retry int) {
+	if r.level < VerbosityMoves {
+		return
+	}
+	if attempt.Failure == FailAmbiguous {
+		fmt.Printf("Ambiguous response: %s (%s)\n", attempt.Response, attempt.Error)
+	} else {
+		fmt.Printf("Invalid response: %s (Error: %s)\n", attempt.Response, attempt.Error)
+	}
+	if retry < maxRetries {
+		fmt.Printf("Retry %d/%d...\n", retry, maxRetries)
+	}
+}
+
+func (r *textRenderer) Moved(game *GameState, move *Move) {
+	switch r.level {
+	case VerbosityMoves:
+		fmt.Printf("Move %d: Player %s (%s) %s → (%d,%d) in %.2fs\n", len(game.Moves), move.Player,
+			game.PlayerConfigs[move.Player].Label(), move.Direction, move.To.Row, move.To.Col, move.Latency)
+	case VerbosityBoard:
+		fmt.Printf("Player %s chose: %s (%.2fs)\n", move.Player, move.Direction, move.Latency)
+		DisplayBoard(game)
+	}
+}
+
+func (r *textRenderer) Eliminated(game *GameState, player string, turn int) {
+	if r.level >= VerbosityMoves {
+		fmt.Printf("❌ Player %s is eliminated (no valid moves)\n", player)
+	}
+}
+
+func (r *textRenderer) GameOver(game *GameState, record *GameRecord) {
+	if record.Error != "" {
+		// Errors are shown at every level
+		fmt.Printf("❌ Game %d: error getting move from LLM: %s\n", record.Number, record.Error)
+		return
+	}
+
+	switch {
+	case r.level == VerbositySummary:
+		result := "draw"
+		if idx := playerIndex(record.Winner); idx >= 0 {
+			result = fmt.Sprintf("Player %s (%s) wins", record.Winner, record.Setup.Players[idx].Label())
+		}
+		fmt.Printf("Game %d: %s after %d moves (%.1fs)\n", record.Number, result, len(record.Moves), record.Duration)
+	case r.level >= VerbosityMoves && record.Winner == "":
+		fmt.Println("\n🤝 Draw! All players eliminated simultaneously.")
+	case r.level >= VerbosityMoves:
+		fmt.Printf("\n🎉 Player %s wins! All other players have been eliminated.\n", record.Winner)
+	}
+}
+
+func (r *textRenderer) Stats(stats *GameStats) {
+	if r.level >= VerbosityMoves {
+		DisplayStats(stats)
+	}
+}
+
+func (r *textRenderer) SetupResult(result *SetupResult) {
+	if r.level >= VerbositySummary {
+		DisplaySetupResult(result)
+	}
+}
+
+func (r *textRenderer) Warning(text string) {
+	// Warnings are shown at every level
+	fmt.Println(text)
+}
+
+func (r *textRenderer) Debug(title, text string) {
+	if title == "" {
+		fmt.Println(text)
+		return
+	}
+	fmt.Printf("=== %s ===\n", title)
+	fmt.Println(text)
+	fmt.Printf("=== END %s ===\n\n", title)
+}
+
+// multiRenderer passes every event on to several renderers, e.g. the
+// terminal output and the spectator server
+type multiRenderer []Renderer
+
+func (m multiRenderer) GameStarted(game *GameState, number int) {
+	for _, r := range m {
+		r.GameStarted(game, number)
+	}
+}
+
+func (m multiRenderer) Thinking(game *GameState, player string, turn int) {
+	for _, r := range m {
+		r.Thinking(game, player, turn)
+	}
+}
+
+func (m multiRenderer) Prompted(game *GameState, player string, prompt string) {
+	for _, r := range m {
+		r.Prompted(game, player, prompt)
+	}
+}
+
+func (m multiRenderer) Rejected(game *GameState, player string, attempt Attempt, retry int) {
+	for _, r := range m {
+		r.Rejected(game, player, attempt, retry)
+	}
+}
+
+func (m multiRenderer) Moved(game *GameState, move *Move) {
+	for _, r := range m {
+		r.Moved(game, move)
+	}
+}
+
+func (m multiRenderer) Eliminated(game *GameState, player string, turn int) {
+	for _, r := range m {
+		r.Eliminated(game, player, turn)
+	}
+}
+
+func (m multiRenderer) GameOver(game *GameState, record *GameRecord) {
+	for _, r := range m {
+		r.GameOver(game, record)
+	}
+}
+
+func (m multiRenderer) Stats(stats *GameStats) {
+	for _, r := range m {
+		r.Stats(stats)
+	}
+}
+
+func (m multiRenderer) SetupResult(result *SetupResult) {
+	for _, r := range m {
+		r.SetupResult(result)
+	}
+}
+
+func (m multiRenderer) Warning(text string) {
+	for _, r := range m {
+		r.Warning(text)
+	}
+}
+
+func (m multiRenderer) Debug(title, text string) {
+	for _, r := range m {
+		r.Debug(title, text)
+	}
+}
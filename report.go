@@ -0,0 +1,552 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PlayerColors gives every seat a distinct color in graphical views
+var PlayerColors = []string{
+	"#e6194b", "#3cb44b", "#4363d8", "#f58231", "#911eb4",
+	"#42d4f4", "#f032e6", "#9a6324", "#808000", "#000075",
+}
+
+// Colors of walls and blocked cells in graphical views
+const (
+	wallColor    = "#444444"
+	blockedColor = "#bbbbbb"
+)
+
+// modelColor picks a stable color for the i-th model of a report
+func modelColor(i int) string {
+	return PlayerColors[i%len(PlayerColors)]
+}
+
+// LeaderboardRow summarizes one model in the report
+type LeaderboardRow struct {
+	Model      string
+	Games      int
+	Wins       int
+	WinRate    float64
+	CILow      float64
+	CIHigh     float64
+	AvgTurns   float64
+	AvgLatency float64
+	Retries    int
+	FollowRate float64
+	Traps      int
+}
+
+// ReplayGame is the data the embedded replay viewer needs for one game
+type ReplayGame struct {
+	Label   string         `json:"label"`
+	Width   int            `json:"width"`
+	Height  int            `json:"height"`
+	Hex     bool           `json:"hex,omitempty"` // Rows shift half a cell right, see Geometry
+	Models  []string       `json:"models"`
+	Starts  [][2]int       `json:"starts"`
+	Walls   [][2]int       `json:"walls,omitempty"`
+	Blocked [][2]int       `json:"blocked,omitempty"`
+	Winner  string         `json:"winner"`
+	Moves   []ReplayMove   `json:"moves"`
+	Elim    map[string]int `json:"elim"`
+}
+
+// ReplayMove is one step of a replay
+type ReplayMove struct {
+	Player   int      `json:"p"`
+	To       [2]int   `json:"to"`
+	Dir      string   `json:"dir"`
+	Latency  float64  `json:"lat"`
+	Response string   `json:"resp"`
+	Reason   string   `json:"why,omitempty"`
+	Tools    []string `json:"tools,omitempty"`
+	Rejected []string `json:"rejected,omitempty"` // Rejected responses before the accepted one
+	Rank     int      `json:"rank"`
+	Options  int      `json:"opts"`
+}
+
+type reportData struct {
+	Title        string
+	Generated    string
+	Games        int
+	Errors       int
+	Leaderboard  []LeaderboardRow
+	Models       []string
+	Matrix       [][]string
+	LatencySVG   template.HTML
+	SurvivalSVG  template.HTML
+	Replays      []ReplayGame
+	Colors       []string
+	WallColor    string
+	BlockedColor string
+}
+
+// RunReportCommand implements the `report` subcommand, which turns stored
+// results into a single self-contained HTML file
+func RunReportCommand(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	path := fs.String("db", "snakes.db", "SQLite database written with -db")
+	run := fs.String("run", "", "Only include games from this run ID")
+	out := fs.String("o", "report.html", "HTML file to write")
+	title := fs.String("title", "LLM Snakes Tournament Report", "Report title")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(*path); err != nil {
+		return fmt.Errorf("database %s: %w", *path, err)
+	}
+	store, err := OpenStore(*path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	games, err := store.LoadGames(*run)
+	if err != nil {
+		return err
+	}
+	if len(games) == 0 {
+		return fmt.Errorf("no games found in %s", *path)
+	}
+
+	html, err := BuildReport(*title, games)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, html, 0o644); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote report for %d games to %s\n", len(games), *out)
+	return nil
+}
+
+// BuildReport renders the HTML report for a set of stored games
+func BuildReport(title string, games []*StoredGame) ([]byte, error) {
+	stats := NewGameStats()
+
+	turns := make(map[string][]int)
+	latencies := make(map[string][]float64)
+	retries := make(map[string]int)
+	data := &reportData{
+		Title:        title,
+		Generated:    time.Now().Format("2006-01-02 15:04"),
+		Games:        len(games),
+		Colors:       PlayerColors,
+		WallColor:    wallColor,
+		BlockedColor: blockedColor,
+	}
+
+	for _, game := range games {
+		record := game.Record
+		RecordGameResult(stats, record)
+
+		moveCounts := make(map[string]int)
+		for _, move := range record.Moves {
+			model := record.Setup.Players[playerIndex(move.Player)].Label()
+			moveCounts[move.Player]++
+			latencies[model] = append(latencies[model], move.Latency)
+			retries[model] += move.Retries
+		}
+		for i, model := range record.Setup.Labels() {
+			turns[model] = append(turns[model], moveCounts[PlayerIDs[i]])
+		}
+
+		data.Replays = append(data.Replays, newReplayGame(game))
+	}
+	data.Errors = stats.Errors
+	data.Models = stats.Models
+
+	for _, model := range stats.Models {
+		row := LeaderboardRow{
+			Model:   model,
+			Games:   stats.ModelGames[model],
+			Wins:    stats.ModelWins[model],
+			Retries: retries[model],
+		}
+		if row.Games > 0 {
+			row.WinRate = float64(row.Wins) / float64(row.Games) * 100
+		}
+		lo, hi := WilsonInterval(row.Wins, row.Games)
+		row.CILow, row.CIHigh = lo*100, hi*100
+		row.AvgTurns = mean(intsToFloats(turns[model]))
+		row.AvgLatency = mean(latencies[model])
+		if h := stats.Hints[model]; h != nil {
+			row.FollowRate = h.FollowRate()
+			row.Traps = h.AvoidableTraps
+		}
+		data.Leaderboard = append(data.Leaderboard, row)
+	}
+	sort.SliceStable(data.Leaderboard, func(i, j int) bool {
+		return data.Leaderboard[i].WinRate > data.Leaderboard[j].WinRate
+	})
+
+	for _, a := range stats.Models {
+		cells := make([]string, len(stats.Models))
+		for j, b := range stats.Models {
+			if a == b {
+				cells[j] = "—"
+				continue
+			}
+			cells[j] = fmt.Sprintf("%d–%d", stats.HeadToHead[a][b], stats.HeadToHead[b][a])
+		}
+		data.Matrix = append(data.Matrix, cells)
+	}
+
+	data.LatencySVG = latencyBoxPlots(stats.Models, latencies)
+	data.SurvivalSVG = survivalCurves(stats.Models, turns)
+
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newReplayGame converts a stored game for the replay viewer
+func newReplayGame(game *StoredGame) ReplayGame {
+	record := game.Record
+	replay := ReplayGame{
+		Label:  fmt.Sprintf("Run %s, game %d: %s", game.RunID, record.Number, strings.Join(record.Setup.Labels(), " vs ")),
+		Width:  record.Width,
+		Height: record.Height,
+		Hex:    record.Setup.Geometry == GeometryHex,
+		Models: record.Setup.Labels(),
+		Winner: record.Winner,
+		Elim:   record.EliminatedAt,
+	}
+	for _, pos := range record.Setup.StartPositions {
+		replay.Starts = append(replay.Starts, [2]int{pos.Row, pos.Col})
+	}
+	for _, pos := range record.Setup.Walls {
+		replay.Walls = append(replay.Walls, [2]int{pos.Row, pos.Col})
+	}
+	for _, pos := range record.Setup.Blocked {
+		replay.Blocked = append(replay.Blocked, [2]int{pos.Row, pos.Col})
+	}
+	for _, move := range record.Moves {
+		var tools []string
+		for _, call := range move.ToolCalls {
+			tools = append(tools, call.String())
+		}
+		var rejected []string
+		for _, attempt := range move.Attempts {
+			if attempt.Failure != "" {
+				rejected = append(rejected, fmt.Sprintf("[%s] %s", attempt.Failure, attempt.Response))
+			}
+		}
+		replay.Moves = append(replay.Moves, ReplayMove{
+			Player:   playerIndex(move.Player),
+			To:       [2]int{move.To.Row, move.To.Col},
+			Dir:      string(move.Direction),
+			Latency:  move.Latency,
+			Response: move.Response,
+			Reason:   move.Reasoning,
+			Tools:    tools,
+			Rejected: rejected,
+			Rank:     move.EngineRank,
+			Options:  move.NumOptions,
+		})
+	}
+	return replay
+}
+
+// latencyBoxPlots draws one horizontal box plot per model (whiskers at the
+// 5th and 95th percentile)
+func latencyBoxPlots(models []string, latencies map[string][]float64) template.HTML {
+	const width, rowHeight, left, right = 720, 36, 160, 20
+	height := rowHeight*len(models) + 40
+
+	maxLatency := 0.0
+	for _, values := range latencies {
+		for _, v := range values {
+			maxLatency = math.Max(maxLatency, v)
+		}
+	}
+	if maxLatency == 0 {
+		maxLatency = 1
+	}
+	x := func(v float64) float64 {
+		return left + v/maxLatency*(width-left-right)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" class="chart">`, width, height)
+	for i, model := range models {
+		values := append([]float64(nil), latencies[model]...)
+		sort.Float64s(values)
+		y := float64(i*rowHeight + 10)
+		mid := y + rowHeight/2 - 4
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" class="label">%s</text>`, left-8, mid+4, template.HTMLEscapeString(model))
+		if len(values) == 0 {
+			continue
+		}
+		p5, q1, med, q3, p95 := percentile(values, 5), percentile(values, 25), percentile(values, 50),
+			percentile(values, 75), percentile(values, 95)
+		color := modelColor(i)
+		fmt.Fprintf(&b, `<line x1="%.1f" x2="%.1f" y1="%.1f" y2="%.1f" stroke="%s"/>`, x(p5), x(p95), mid, mid, color)
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%d" fill="%s" fill-opacity="0.35" stroke="%s"><title>median %.2fs, IQR %.2f-%.2fs, n=%d</title></rect>`,
+			x(q1), mid-10, math.Max(1, x(q3)-x(q1)), 20, color, color, med, q1, q3, len(values))
+		fmt.Fprintf(&b, `<line x1="%.1f" x2="%.1f" y1="%.1f" y2="%.1f" stroke="%s" stroke-width="2"/>`, x(med), x(med), mid-10, mid+10, color)
+	}
+
+	axisY := rowHeight*len(models) + 14
+	fmt.Fprintf(&b, `<line x1="%d" x2="%d" y1="%d" y2="%d" class="axis"/>`, left, width-right, axisY, axisY)
+	for i := 0; i <= 4; i++ {
+		v := maxLatency * float64(i) / 4
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" class="tick">%.1fs</text>`, x(v), axisY+16, v)
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// survivalCurves plots, per model, the share of its games in which it made
+// at least N moves
+func survivalCurves(models []string, turns map[string][]int) template.HTML {
+	const width, height, left, bottom, top, right = 720, 300, 50, 30, 10, 20
+
+	maxTurns := 1
+	for _, values := range turns {
+		for _, v := range values {
+			if v > maxTurns {
+				maxTurns = v
+			}
+		}
+	}
+	x := func(t int) float64 {
+		return left + float64(t)/float64(maxTurns)*(width-left-right)
+	}
+	y := func(share float64) float64 {
+		return top + (1-share)*(height-top-bottom)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" class="chart">`, width, height)
+	fmt.Fprintf(&b, `<line x1="%d" x2="%d" y1="%.1f" y2="%.1f" class="axis"/>`, left, width-right, y(0), y(0))
+	fmt.Fprintf(&b, `<line x1="%d" x2="%d" y1="%.1f" y2="%.1f" class="axis"/>`, left, left, y(0), y(1))
+	for i := 0; i <= 4; i++ {
+		share := float64(i) / 4
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" class="tick">%.0f%%</text>`, left-6, y(share)+4, share*100)
+		t := maxTurns * i / 4
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" class="tick">%d</text>`, x(t), height-10, t)
+	}
+
+	for i, model := range models {
+		values := turns[model]
+		if len(values) == 0 {
+			continue
+		}
+		points := make([]string, 0, maxTurns+1)
+		for t := 0; t <= maxTurns; t++ {
+			alive := 0
+			for _, v := range values {
+				if v >= t {
+					alive++
+				}
+			}
+			share := float64(alive) / float64(len(values))
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x(t), y(share)))
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"><title>%s</title></polyline>`,
+			modelColor(i), strings.Join(points, " "), template.HTMLEscapeString(model))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// percentile returns the p-th percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(idx))
+	hi := int(math.Ceil(idx))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(idx-float64(lo))
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
+
+func intsToFloats(values []int) []float64 {
+	floats := make([]float64, len(values))
+	for i, v := range values {
+		floats[i] = float64(v)
+	}
+	return floats
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"color": modelColor,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 960px; color: #222; }
+h1 { margin-bottom: 0; }
+.meta { color: #666; margin-top: 0.2em; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+th { background: #f3f3f3; }
+.swatch { display: inline-block; width: 10px; height: 10px; margin-right: 6px; border-radius: 2px; }
+.chart { width: 100%; max-width: 720px; font-size: 11px; }
+.chart .label { text-anchor: end; }
+.chart .tick { text-anchor: middle; fill: #666; }
+.chart .axis { stroke: #999; }
+.legend span { margin-right: 1.5em; }
+#board { display: grid; gap: 1px; background: #ccc; border: 1px solid #ccc; width: max-content; margin: 1em 0; }
+#board div { width: 24px; height: 24px; background: #fff; font: bold 13px monospace; display: flex; align-items: center; justify-content: center; color: #fff; }
+#controls button { min-width: 2.5em; }
+#info { font-family: monospace; white-space: pre-wrap; background: #f7f7f7; padding: 0.6em; min-height: 3em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">{{.Games}} games, {{.Errors}} aborted by errors. Generated {{.Generated}}.</p>
+
+<h2>Leaderboard</h2>
+<table>
+<tr><th>Model</th><th>Games</th><th>Wins</th><th>Win rate</th><th>95% CI</th><th>Avg moves</th><th>Avg latency</th><th>Retries</th><th>Top move</th><th>Avoidable traps</th></tr>
+{{range .Leaderboard}}<tr><td>{{.Model}}</td><td>{{.Games}}</td><td>{{.Wins}}</td><td>{{printf "%.1f%%" .WinRate}}</td><td>{{printf "%.1f–%.1f%%" .CILow .CIHigh}}</td><td>{{printf "%.1f" .AvgTurns}}</td><td>{{printf "%.2fs" .AvgLatency}}</td><td>{{.Retries}}</td><td>{{printf "%.1f%%" .FollowRate}}</td><td>{{.Traps}}</td></tr>
+{{end}}</table>
+<p>Top move is the share of moves with a choice where the model picked the move ranked first (⭐) in its prompt; avoidable traps counts DEATH TRAP moves chosen while a safer move existed.</p>
+
+{{if gt (len .Models) 1}}
+<h2>Win Matrix</h2>
+<p>Each cell shows row model wins – column model wins in games where both played.</p>
+<table>
+<tr><th></th>{{range .Models}}<th>{{.}}</th>{{end}}</tr>
+{{range $i, $row := .Matrix}}<tr><th>{{index $.Models $i}}</th>{{range $row}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}
+
+<p class="legend">{{range $i, $m := .Models}}<span><i class="swatch" style="background: {{color $i}}"></i>{{$m}}</span>{{end}}</p>
+
+<h2>Response Latency</h2>
+<p>Box from first to third quartile with the median marked; whiskers span the 5th to 95th percentile.</p>
+{{.LatencySVG}}
+
+<h2>Survival</h2>
+<p>Share of games in which each model made at least N moves.</p>
+{{.SurvivalSVG}}
+
+<h2>Replays</h2>
+<select id="game"></select>
+<div id="controls">
+<button id="first">⏮</button><button id="prev">◀</button><button id="play">▶ Play</button><button id="next">▶</button><button id="last">⏭</button>
+<input id="step" type="range" min="0" value="0"> <span id="counter"></span>
+</div>
+<div id="board"></div>
+<div id="info"></div>
+
+<script>
+const GAMES = {{.Replays}};
+const COLORS = {{.Colors}};
+const WALL = {{.WallColor}}, BLOCKED = {{.BlockedColor}};
+const IDS = ["1", "2", "3", "4", "5", "6", "7", "8", "9", "A"];
+const $ = (id) => document.getElementById(id);
+let game = null, step = 0, timer = null;
+
+GAMES.forEach((g, i) => {
+	const opt = document.createElement("option");
+	opt.value = i;
+	opt.textContent = g.label + (g.winner === "" ? " (draw)" : g.winner === "error" ? " (error)" : " (Player " + g.winner + " won)");
+	$("game").appendChild(opt);
+});
+
+function load(i) {
+	game = GAMES[i];
+	game.moves = game.moves || [];
+	$("step").max = game.moves.length;
+	$("board").style.gridTemplateColumns = "repeat(" + game.width + ", 24px)";
+	// Hex rows shift half a cell right of the row above
+	$("board").style.paddingRight = game.hex ? (game.height - 1) * 12.5 + "px" : "";
+	$("board").style.background = game.hex ? "none" : "";
+	show(0);
+}
+
+function show(n) {
+	step = Math.max(0, Math.min(n, game.moves.length));
+	const cells = [];
+	for (let i = 0; i < game.width * game.height; i++) cells.push({ p: -1, head: false });
+	(game.walls || []).forEach((w) => { cells[w[0] * game.width + w[1]].fill = WALL; });
+	(game.blocked || []).forEach((b) => { cells[b[0] * game.width + b[1]].fill = BLOCKED; });
+	const heads = game.starts.map((s) => s.slice());
+	heads.forEach((s, p) => { cells[s[0] * game.width + s[1]] = { p: p, head: true }; });
+	for (let i = 0; i < step; i++) {
+		const m = game.moves[i];
+		cells[heads[m.p][0] * game.width + heads[m.p][1]].head = false;
+		heads[m.p] = m.to;
+		cells[m.to[0] * game.width + m.to[1]] = { p: m.p, head: true };
+	}
+	const board = $("board");
+	board.innerHTML = "";
+	cells.forEach((c, i) => {
+		const d = document.createElement("div");
+		if (game.hex) {
+			d.style.transform = "translateX(" + Math.floor(i / game.width) * 12.5 + "px)";
+			d.style.borderRadius = "40%";
+			d.style.outline = "1px solid #ccc";
+		}
+		if (c.p >= 0) {
+			d.style.background = COLORS[c.p % COLORS.length];
+			d.style.opacity = c.head ? "1" : "0.45";
+			if (c.head) d.textContent = IDS[c.p];
+		} else if (c.fill) {
+			d.style.background = c.fill;
+		}
+		board.appendChild(d);
+	});
+	$("step").value = step;
+	$("counter").textContent = "move " + step + " / " + game.moves.length;
+	if (step === 0) {
+		$("info").textContent = game.models.map((m, p) => "Player " + IDS[p] + ": " + m).join("\n");
+	} else {
+		const m = game.moves[step - 1];
+		$("info").textContent = "Player " + IDS[m.p] + " (" + game.models[m.p] + ") moved " + m.dir +
+			" in " + m.lat.toFixed(2) + "s" + (m.rank ? ", engine rank " + m.rank + "/" + m.opts : "") +
+			(m.tools ? "\nTools:\n  " + m.tools.join("\n  ") : "") +
+			(m.rejected ? "\nRejected:\n  " + m.rejected.join("\n  ") : "") +
+			(m.why ? "\nReasoning: " + m.why : "") + "\nResponse: " + m.resp;
+	}
+}
+
+function toggle() {
+	if (timer) { clearInterval(timer); timer = null; $("play").textContent = "▶ Play"; return; }
+	if (step >= game.moves.length) show(0);
+	$("play").textContent = "⏸ Pause";
+	timer = setInterval(() => { if (step >= game.moves.length) toggle(); else show(step + 1); }, 300);
+}
+
+$("game").onchange = (e) => { if (timer) toggle(); load(+e.target.value); };
+$("first").onclick = () => show(0);
+$("prev").onclick = () => show(step - 1);
+$("next").onclick = () => show(step + 1);
+$("last").onclick = () => show(game.moves.length);
+$("play").onclick = toggle;
+$("step").oninput = (e) => show(+e.target.value);
+if (GAMES.length) load(0);
+</script>
+</body>
+</html>
+`))
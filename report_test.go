@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPercentile(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5}
+	tests := []struct {
+		p, want float64
+	}{
+		{0, 1}, {25, 2}, {50, 3}, {62.5, 3.5}, {95, 4.8}, {100, 5},
+	}
+	for _, tt := range tests {
+		if got := percentile(values, tt.p); fmt.Sprintf("%.6f", got) != fmt.Sprintf("%.6f", tt.want) {
+			t.Errorf("percentile(%v, %g) = %g, want %g", values, tt.p, got, tt.want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile of no values = %g, want 0", got)
+	}
+	if got := percentile([]float64{7}, 95); got != 7 {
+		t.Errorf("percentile of one value = %g, want 7", got)
+	}
+}
+
+func TestNewReplayGame(t *testing.T) {
+	replay := newReplayGame(&StoredGame{RunID: "run-a", Record: testRecord(2)})
+
+	if replay.Label != "Run run-a, game 2: llama3.2 vs mistral [agent]" {
+		t.Errorf("label %q", replay.Label)
+	}
+	if !replay.Hex || replay.Width != 5 || replay.Height != 4 || replay.Winner != "1" {
+		t.Errorf("replay of a %dx%d board (hex %v) won by %q, want 5x4 hex won by 1",
+			replay.Width, replay.Height, replay.Hex, replay.Winner)
+	}
+	if fmt.Sprint(replay.Starts, replay.Walls, replay.Blocked) != "[[1 1] [2 3]] [[0 4]] [[3 0]]" {
+		t.Errorf("starts, walls and blocked cells %v %v %v", replay.Starts, replay.Walls, replay.Blocked)
+	}
+	if len(replay.Moves) != 2 {
+		t.Fatalf("%d replay moves, want 2", len(replay.Moves))
+	}
+
+	first, second := replay.Moves[0], replay.Moves[1]
+	if first.Player != 0 || first.To != [2]int{1, 2} || first.Dir != "right" || first.Rank != 1 || first.Options != 6 {
+		t.Errorf("first move %+v", first)
+	}
+	if fmt.Sprint(first.Rejected) != "[[ambiguous] up or right]" || len(first.Tools) != 0 {
+		t.Errorf("first move rejected %q and tools %q, want one ambiguous answer and no tools", first.Rejected, first.Tools)
+	}
+	if second.Player != 1 || len(second.Rejected) != 0 || len(second.Tools) != 2 ||
+		second.Tools[0] != "legal_moves({}) → left, down-left" {
+		t.Errorf("second move %+v", second)
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	games := []*StoredGame{
+		{RunID: "run-a", Record: testRecord(1)},
+		{RunID: "run-a", Record: testRecord(2)},
+	}
+	games[1].Record.Winner = "2"
+
+	html, err := BuildReport("<Ladder> & co", games)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(html)
+	for _, want := range []string{
+		"&lt;Ladder&gt; &amp; co",
+		"llama3.2", "mistral [agent]",
+		"1–1", // Head-to-head
+		`class="chart"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("report lacks %q", want)
+		}
+	}
+	if strings.Contains(page, "<Ladder>") {
+		t.Error("report title is not escaped")
+	}
+}
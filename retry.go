@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Attempt is one response to a move prompt, rejected or accepted
+type Attempt struct {
+	Response string
+	Failure  ParseFailure // Why it was rejected, "" for the accepted response
+	Error    string       // The parser's explanation of the rejection
+	Latency  float64      // Seconds
+}
+
+// rejectedAttempt records a response the parser rejected
+func rejectedAttempt(response string, latency float64, err error) Attempt {
+	attempt := Attempt{Response: response, Failure: FailUnparseable, Error: err.Error(), Latency: latency}
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		attempt.Failure = parseErr.Failure
+	}
+	return attempt
+}
+
+// rejection explains to a player why its response was not accepted. An
+// illegal move is explained with the reason the direction is blocked.
+func rejection(game *GameState, player string, validMoves []Direction, err error) string {
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		return fmt.Sprintf("Your response could not be used (%v).", err)
+	}
+
+	switch parseErr.Failure {
+	case FailIllegal:
+		dir := parseErr.Direction
+		if reason, blocked := getBlockedMoves(game, player, validMoves)[dir]; blocked {
+			to := getNewPosition(game, game.PlayerPos[player], dir)
+			return fmt.Sprintf("You chose %s, but that move is blocked: (%d,%d) is %s.", dir, to.Row, to.Col, reason)
+		}
+		return fmt.Sprintf("You chose %s, which is not a legal move.", dir)
+
+	case FailAmbiguous:
+		names := make([]string, len(parseErr.Candidates))
+		for i, dir := range parseErr.Candidates {
+			names[i] = string(dir)
+		}
+		return fmt.Sprintf("Your response was ambiguous: it names %s, but you can only make one move.",
+			strings.Join(names, " and "))
+	}
+	return "I could not find a direction in your response."
+}
+
+// correction is the corrective turn after a rejected response: why it was
+// rejected, and how to answer
+func correction(game *GameState, player string, validMoves []Direction, err error) string {
+	ask := fmt.Sprintf("Respond with exactly one word, your move: %s", formatValidMoves(validMoves))
+	if game.PlayerConfigs[player].Reasoning {
+		ask = fmt.Sprintf("End your response with a line MOVE: <direction>, using one of: %s", formatValidMoves(validMoves))
+	}
+	return rejection(game, player, validMoves, err) + " " + ask
+}
+
+// retryPrompt builds the prompt for a retry on a backend without chat: the
+// original prompt, the rejected response and the correction. Only the last
+// rejected response is repeated, and a long chain of thought not at all.
+func retryPrompt(prompt, response, correction string, reasoning bool) string {
+	prompt = strings.TrimRight(prompt, "\n")
+	if reasoning {
+		return prompt + "\n\n" + correction
+	}
+	return fmt.Sprintf("%s\n\nYour previous response was: %s\n\n%s", prompt, strings.TrimSpace(response), correction)
+}
+
+// CallChat sends a conversation to the chat backend, constraining the
+// answer like CallLLM
+func CallChat(messages []ChatMessage, playerConfig *PlayerConfig, validMoves []Direction) (string, error) {
+	chat, ok := backend.(ChatBackend)
+	if !ok {
+		return "", fmt.Errorf("the %s API does not support conversations", backend.Name())
+	}
+	reply, err := chat.Chat(&ChatRequest{
+		Model:       playerConfig.Model,
+		Temperature: playerConfig.Temperature,
+		Messages:    messages,
+		Output:      playerConfig.Output,
+		ValidMoves:  validMoves,
+		Reasoning:   playerConfig.Reasoning,
+	})
+	if err != nil {
+		return "", err
+	}
+	return reply.Content, nil
+}
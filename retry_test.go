@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCorrection(t *testing.T) {
+	// Both players made one move on a 4x3 board with a wall:
+	//
+	//	a 1 . #
+	//	. . . .
+	//	. . 2 b
+	setup := &GameSetup{
+		Width: 4, Height: 3,
+		Walls:          []Position{{0, 3}},
+		StartPositions: []Position{{0, 0}, {2, 3}},
+		Players:        []*PlayerConfig{{Model: "a"}, {Model: "b", Reasoning: true}},
+	}
+	game := InitGame(setup)
+	MakeMove(game, "1", Right)
+	MakeMove(game, "2", Left)
+
+	tests := []struct {
+		player, response string
+		want             string
+	}{
+		{"1", "left", "You chose left, but that move is blocked: (0,0) is already visited. " +
+			"Respond with exactly one word, your move: down, right"},
+		{"1", "up", "You chose up, but that move is blocked: (-1,1) is out of bounds. " +
+			"Respond with exactly one word, your move: down, right"},
+		{"1", "down or right", "Your response was ambiguous: it names down and right, but you can only make one move. " +
+			"Respond with exactly one word, your move: down, right"},
+		{"1", "no idea", "I could not find a direction in your response. " +
+			"Respond with exactly one word, your move: down, right"},
+		{"2", "right", "You chose right, but that move is blocked: (2,3) is already visited. " +
+			"End your response with a line MOVE: <direction>, using one of: up, left"},
+	}
+	for _, tt := range tests {
+		validMoves := GetValidMoves(game, tt.player)
+		_, err := ParseDirection(tt.response, moveTargets(game, tt.player), validMoves)
+		if err == nil {
+			t.Fatalf("ParseDirection(%q) accepted the response", tt.response)
+		}
+		if got := correction(game, tt.player, validMoves, err); got != tt.want {
+			t.Errorf("correction for %q =\n%s\nwant\n%s", tt.response, got, tt.want)
+		}
+	}
+
+	// Errors from outside the parser, e.g. invalid JSON, are passed on
+	err := fmt.Errorf("unexpected end of JSON input")
+	if got := rejection(game, "1", GetValidMoves(game, "1"), err); got != "Your response could not be used (unexpected end of JSON input)." {
+		t.Errorf("rejection of a non-parse error = %q", got)
+	}
+	if attempt := rejectedAttempt("{", 0.5, err); attempt.Failure != FailUnparseable || attempt.Error != err.Error() {
+		t.Errorf("rejectedAttempt = %+v, want an unparseable attempt", attempt)
+	}
+}
+
+func TestRetryPrompt(t *testing.T) {
+	if got, want := retryPrompt("Your move?\n", " upp \n", "Try again.", false),
+		"Your move?\n\nYour previous response was: upp\n\nTry again."; got != want {
+		t.Errorf("retryPrompt = %q, want %q", got, want)
+	}
+	if got, want := retryPrompt("Your move?\n", "a long chain of thought", "Try again.", true),
+		"Your move?\n\nTry again."; got != want {
+		t.Errorf("retryPrompt with reasoning = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// z score for 95% confidence intervals
+const confidenceZ = 1.96
+
+// WilsonInterval returns the 95% Wilson score interval for a win rate of
+// wins out of games. Unlike the normal approximation it stays inside [0, 1]
+// and behaves sensibly for small samples and rates near 0% or 100%.
+func WilsonInterval(wins, games int) (float64, float64) {
+	if games == 0 {
+		return 0, 1
+	}
+
+	n := float64(games)
+	p := float64(wins) / n
+	z2 := confidenceZ * confidenceZ
+
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	margin := confidenceZ * math.Sqrt(p*(1-p)/n+z2/(4*n*n)) / (1 + z2/n)
+
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// BinomialTest returns the two-sided exact p-value of observing k successes
+// out of n trials when each trial succeeds with probability 0.5. Used as a
+// sign test for head-to-head results, where draws are left out.
+func BinomialTest(k, n int) float64 {
+	if n == 0 {
+		return 1
+	}
+
+	// Sum the probabilities of every outcome at most as likely as k
+	observed := binomialLogProb(k, n)
+	p := 0.0
+	for i := 0; i <= n; i++ {
+		if lp := binomialLogProb(i, n); lp <= observed+1e-9 {
+			p += math.Exp(lp)
+		}
+	}
+
+	return math.Min(1, p)
+}
+
+// binomialLogProb returns log P(X = k) for X ~ Binomial(n, 0.5)
+func binomialLogProb(k, n int) float64 {
+	lnN, _ := math.Lgamma(float64(n + 1))
+	lnK, _ := math.Lgamma(float64(k + 1))
+	lnNK, _ := math.Lgamma(float64(n - k + 1))
+	return lnN - lnK - lnNK + float64(n)*math.Log(0.5)
+}
+
+// formatInterval renders a Wilson interval as percentages
+func formatInterval(wins, games int) string {
+	lo, hi := WilsonInterval(wins, games)
+	return fmt.Sprintf("95%% CI %.1f-%.1f%%", lo*100, hi*100)
+}
+
+// SPRTResult is the outcome of a sequential probability ratio test
+type SPRTResult int
+
+const (
+	SPRTContinue     SPRTResult = iota // Not enough evidence yet
+	SPRTFirstBetter                    // First model is significantly stronger
+	SPRTSecondBetter                   // Second model is significantly stronger
+	SPRTEqual                          // Neither model is stronger by the tested margin
+)
+
+// SPRT decides after each game whether a match between two models can stop.
+// Each model is tested for H1 "wins a decisive game with probability p1"
+// against H0 "wins with probability 0.5"; the match ends as soon as one model
+// is accepted as stronger, or both are rejected.
+func SPRT(firstWins, secondWins int, p1, alpha, beta float64) SPRTResult {
+	upper := math.Log((1 - beta) / alpha)
+	lower := math.Log(beta / (1 - alpha))
+
+	win := math.Log(p1 / 0.5)
+	loss := math.Log((1 - p1) / 0.5)
+	llrFirst := float64(firstWins)*win + float64(secondWins)*loss
+	llrSecond := float64(secondWins)*win + float64(firstWins)*loss
+
+	switch {
+	case llrFirst >= upper:
+		return SPRTFirstBetter
+	case llrSecond >= upper:
+		return SPRTSecondBetter
+	case llrFirst <= lower && llrSecond <= lower:
+		return SPRTEqual
+	}
+	return SPRTContinue
+}
+
+// CheckSPRT runs the SPRT on the head-to-head record of the two models in
+// the match and reports whether play can stop. It only applies when exactly
+// two distinct models are playing.
+func CheckSPRT(stats *GameStats) (bool, string) {
+	if len(stats.Models) != 2 {
+		return false, ""
+	}
+
+	first, second := stats.Models[0], stats.Models[1]
+	firstWins := stats.HeadToHead[first][second]
+	secondWins := stats.HeadToHead[second][first]
+
+	switch SPRT(firstWins, secondWins, sprtP1, sprtAlpha, sprtBeta) {
+	case SPRTFirstBetter:
+		return true, fmt.Sprintf("%s is significantly stronger than %s (%d-%d)", first, second, firstWins, secondWins)
+	case SPRTSecondBetter:
+		return true, fmt.Sprintf("%s is significantly stronger than %s (%d-%d)", second, first, secondWins, firstWins)
+	case SPRTEqual:
+		return true, fmt.Sprintf("neither %s nor %s wins more than %.0f%% of decisive games (%d-%d)",
+			first, second, sprtP1*100, firstWins, secondWins)
+	}
+	return false, ""
+}
+
+// DisplayHeadToHead shows the pairwise record between models together with
+// the sign-test p-value of each pairing
+func DisplayHeadToHead(stats *GameStats) {
+	if len(stats.Models) < 2 {
+		return
+	}
+
+	fmt.Println("Head-to-head (sign test, draws excluded):")
+	for i, a := range stats.Models {
+		for _, b := range stats.Models[i+1:] {
+			aWins := stats.HeadToHead[a][b]
+			bWins := stats.HeadToHead[b][a]
+			p := BinomialTest(aWins, aWins+bWins)
+			verdict := "not significant"
+			if p < 0.05 {
+				verdict = "significant"
+			}
+			fmt.Printf("  %s vs %s: %d-%d (p=%.3f, %s)\n", a, b, aWins, bWins, p, verdict)
+		}
+	}
+}
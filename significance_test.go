@@ -0,0 +1,66 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWilsonInterval(t *testing.T) {
+	tests := []struct {
+		wins, games int
+		lo, hi      float64
+	}{
+		{0, 0, 0, 1},
+		{27, 50, 0.4040, 0.6703},
+		{1, 2, 0.0945, 0.9055},
+		{0, 2, 0, 0.6576},
+		{10, 10, 0.7225, 1},
+	}
+	for _, tt := range tests {
+		lo, hi := WilsonInterval(tt.wins, tt.games)
+		if math.Abs(lo-tt.lo) > 1e-4 || math.Abs(hi-tt.hi) > 1e-4 {
+			t.Errorf("WilsonInterval(%d, %d) = %.4f-%.4f, want %.4f-%.4f", tt.wins, tt.games, lo, hi, tt.lo, tt.hi)
+		}
+	}
+}
+
+func TestBinomialTest(t *testing.T) {
+	tests := []struct {
+		k, n int
+		want float64
+	}{
+		{0, 0, 1},
+		{5, 10, 1},
+		{0, 5, 2.0 / 32},
+		{5, 5, 2.0 / 32},
+		{8, 10, 112.0 / 1024},
+		{2, 10, 112.0 / 1024},
+		{1, 2, 1},
+	}
+	for _, tt := range tests {
+		if got := BinomialTest(tt.k, tt.n); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("BinomialTest(%d, %d) = %g, want %g", tt.k, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestSPRT(t *testing.T) {
+	// With p1 = 0.6 and alpha = beta = 0.05 a model needs a log likelihood
+	// ratio of ln 19 ≈ 2.944: 17 straight wins, but not 16
+	tests := []struct {
+		first, second int
+		want          SPRTResult
+	}{
+		{0, 0, SPRTContinue},
+		{16, 0, SPRTContinue},
+		{17, 0, SPRTFirstBetter},
+		{0, 20, SPRTSecondBetter},
+		{50, 50, SPRTContinue},
+		{80, 80, SPRTEqual},
+	}
+	for _, tt := range tests {
+		if got := SPRT(tt.first, tt.second, 0.6, 0.05, 0.05); got != tt.want {
+			t.Errorf("SPRT(%d, %d) = %d, want %d", tt.first, tt.second, got, tt.want)
+		}
+	}
+}
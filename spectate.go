@@ -0,0 +1,498 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// spectateHistory is the number of finished games the spectator server keeps
+const spectateHistory = 100
+
+// SpectatorGame is a snapshot of one game as the browser view shows it
+type SpectatorGame struct {
+	Number  int               `json:"number"`
+	Width   int               `json:"width"`
+	Height  int               `json:"height"`
+	Board   []string          `json:"board"`         // Rows in the ASCII encoding
+	Hex     bool              `json:"hex,omitempty"` // Rows shift half a cell right, see Geometry
+	Moves   int               `json:"moves"`
+	Last    *[2]int           `json:"last,omitempty"` // Cell the last move went to
+	LastDir Direction         `json:"lastDir,omitempty"`
+	Turn    string            `json:"turn,omitempty"` // Player whose move is awaited
+	Status  string            `json:"status"`
+	Done    bool              `json:"done"`
+	Winner  string            `json:"winner,omitempty"`
+	Players []SpectatorPlayer `json:"players"`
+	Updated time.Time         `json:"updated"`
+}
+
+// SpectatorPlayer is a player's state with its latest prompt and response
+type SpectatorPlayer struct {
+	ID        string  `json:"id"`
+	Model     string  `json:"model"`
+	Alive     bool    `json:"alive"`
+	Moves     int     `json:"moves"`
+	Prompt    string  `json:"prompt"`
+	Response  string  `json:"response"`
+	Reasoning string  `json:"reasoning,omitempty"` // Reasoning behind the latest move, if the player gave any
+	Rejected  string  `json:"rejected,omitempty"`  // Why the latest response was rejected
+	Latency   float64 `json:"latency"`
+}
+
+// Spectator serves a live browser view of the games being played. It is a
+// Renderer: every event updates a snapshot of the game, which is pushed to
+// the connected browsers as a Server-Sent Event.
+type Spectator struct {
+	server      *http.Server
+	mu          sync.Mutex
+	games       map[int]*SpectatorGame
+	current     map[*GameState]*SpectatorGame
+	subscribers map[*spectatorSubscriber]bool
+}
+
+// spectatorSubscriber is one connected browser and the games it has not
+// been sent since they changed. Slow clients skip intermediate snapshots.
+type spectatorSubscriber struct {
+	dirty  map[int]bool
+	notify chan struct{}
+}
+
+// StartSpectator listens on addr and serves the spectator view in the
+// background
+func StartSpectator(addr string) (*Spectator, error) {
+	s := &Spectator{
+		games:       make(map[int]*SpectatorGame),
+		current:     make(map[*GameState]*SpectatorGame),
+		subscribers: make(map[*spectatorSubscriber]bool),
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.serveIndex)
+	mux.HandleFunc("/events", s.serveEvents)
+	mux.HandleFunc("/games", s.serveGames)
+	s.server = &http.Server{Handler: mux}
+	go func() {
+		if err := s.server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("Spectator server stopped: %v\n", err)
+		}
+	}()
+
+	fmt.Printf("Spectator view at %s\n", spectatorURL(listener.Addr()))
+	return s, nil
+}
+
+// Close stops the server, disconnecting the browsers
+func (s *Spectator) Close() error {
+	return s.server.Close()
+}
+
+// spectatorURL is the address to open in a browser, with the host's LAN
+// address when the server listens on all interfaces
+func spectatorURL(addr net.Addr) string {
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return "http://" + addr.String()
+	}
+	host := tcp.IP.String()
+	if tcp.IP.IsUnspecified() {
+		host = "localhost"
+		if addrs, err := net.InterfaceAddrs(); err == nil {
+			for _, a := range addrs {
+				if ip, ok := a.(*net.IPNet); ok && !ip.IP.IsLoopback() && ip.IP.To4() != nil {
+					host = ip.IP.String()
+					break
+				}
+			}
+		}
+	}
+	return fmt.Sprintf("http://%s/", net.JoinHostPort(host, fmt.Sprint(tcp.Port)))
+}
+
+// update applies a change to a game's snapshot and notifies the browsers
+func (s *Spectator) update(game *GameState, change func(view *SpectatorGame)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	view, ok := s.current[game]
+	if !ok {
+		return
+	}
+	change(view)
+	snapshotBoard(view, game)
+	view.Updated = time.Now()
+	for sub := range s.subscribers {
+		sub.dirty[view.Number] = true
+		select {
+		case sub.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// snapshotBoard copies the board and player states into a snapshot
+func snapshotBoard(view *SpectatorGame, game *GameState) {
+	view.Board = make([]string, game.Height)
+	for row := range view.Board {
+		var line strings.Builder
+		for col := 0; col < game.Width; col++ {
+			line.WriteString(cellCode(game, Position{row, col}))
+		}
+		view.Board[row] = line.String()
+	}
+	view.Moves = len(game.Moves)
+	if view.Moves > 0 {
+		last := game.Moves[view.Moves-1]
+		view.Last = &[2]int{last.To.Row, last.To.Col}
+		view.LastDir = last.Direction
+	}
+	for i := range view.Players {
+		view.Players[i].Alive = game.ActivePlayers[view.Players[i].ID]
+	}
+}
+
+// player finds a player in a snapshot
+func (view *SpectatorGame) player(id string) *SpectatorPlayer {
+	return &view.Players[playerIndex(id)]
+}
+
+func (s *Spectator) GameStarted(game *GameState, number int) {
+	view := &SpectatorGame{Number: number, Width: game.Width, Height: game.Height,
+		Hex: game.Geometry == GeometryHex, Status: "starting"}
+	for i := 0; i < game.NumPlayers; i++ {
+		id := PlayerIDs[i]
+		view.Players = append(view.Players, SpectatorPlayer{ID: id, Model: game.PlayerConfigs[id].Label()})
+	}
+
+	s.mu.Lock()
+	s.current[game] = view
+	s.games[number] = view
+	s.mu.Unlock()
+	s.update(game, func(*SpectatorGame) {})
+}
+
+func (s *Spectator) Thinking(game *GameState, player string, turn int) {
+	s.update(game, func(view *SpectatorGame) {
+		view.Turn = player
+		view.Status = fmt.Sprintf("Player %s is thinking…", player)
+	})
+}
+
+func (s *Spectator) Prompted(game *GameState, player string, prompt string) {
+	s.update(game, func(view *SpectatorGame) {
+		p := view.player(player)
+		p.Prompt, p.Response, p.Reasoning, p.Rejected = prompt, "", "", ""
+	})
+}
+
+func (s *Spectator) Rejected(game *GameState, player string, attempt Attempt, retry int) {
+	s.update(game, func(view *SpectatorGame) {
+		p := view.player(player)
+		p.Response = attempt.Response
+		p.Rejected = fmt.Sprintf("%s: %s", attempt.Failure, attempt.Error)
+		p.Latency = attempt.Latency
+		view.Status = fmt.Sprintf("Player %s is retrying (%d/%d)", player, retry, maxRetries)
+	})
+}
+
+func (s *Spectator) Moved(game *GameState, move *Move) {
+	s.update(game, func(view *SpectatorGame) {
+		p := view.player(move.Player)
+		p.Moves++
+		p.Response, p.Reasoning, p.Rejected = move.Response, move.Reasoning, ""
+		p.Latency = move.Latency
+		view.Turn = ""
+		view.Status = fmt.Sprintf("Player %s moved %s", move.Player, move.Direction)
+	})
+}
+
+func (s *Spectator) Eliminated(game *GameState, player string, turn int) {
+	s.update(game, func(view *SpectatorGame) {
+		view.Status = fmt.Sprintf("Player %s is eliminated", player)
+	})
+}
+
+func (s *Spectator) GameOver(game *GameState, record *GameRecord) {
+	s.update(game, func(view *SpectatorGame) {
+		view.Done = true
+		view.Turn = ""
+		view.Winner = record.Winner
+		switch {
+		case record.Error != "":
+			view.Status = "Error: " + record.Error
+		case record.Winner == "":
+			view.Status = "Draw"
+		default:
+			view.Status = fmt.Sprintf("Player %s wins", record.Winner)
+		}
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.current, game)
+	finished := 0
+	for _, view := range s.games {
+		if view.Done {
+			finished++
+		}
+	}
+	for ; finished > spectateHistory; finished-- {
+		delete(s.games, s.oldestFinished())
+	}
+}
+
+// oldestFinished returns the number of the earliest finished game, or -1
+func (s *Spectator) oldestFinished() int {
+	oldest := -1
+	for number, view := range s.games {
+		if view.Done && (oldest < 0 || number < oldest) {
+			oldest = number
+		}
+	}
+	return oldest
+}
+
+func (s *Spectator) Stats(stats *GameStats)          {}
+func (s *Spectator) SetupResult(result *SetupResult) {}
+func (s *Spectator) Warning(text string)             {}
+func (s *Spectator) Debug(title, text string)        {}
+
+// snapshots encodes games as JSON, in order of their numbers
+func (s *Spectator) snapshots(numbers []int) [][]byte {
+	sort.Ints(numbers)
+	var events [][]byte
+	for _, number := range numbers {
+		if view, ok := s.games[number]; ok {
+			data, _ := json.Marshal(view)
+			events = append(events, data)
+		}
+	}
+	return events
+}
+
+// serveEvents streams every game snapshot, then each change as it happens
+func (s *Spectator) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	sub := &spectatorSubscriber{dirty: make(map[int]bool), notify: make(chan struct{}, 1)}
+	s.mu.Lock()
+	for number := range s.games {
+		sub.dirty[number] = true
+	}
+	s.subscribers[sub] = true
+	s.mu.Unlock()
+	sub.notify <- struct{}{}
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, sub)
+		s.mu.Unlock()
+	}()
+
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-sub.notify:
+			s.mu.Lock()
+			numbers := make([]int, 0, len(sub.dirty))
+			for number := range sub.dirty {
+				numbers = append(numbers, number)
+			}
+			sub.dirty = make(map[int]bool)
+			events := s.snapshots(numbers)
+			s.mu.Unlock()
+			for _, data := range events {
+				fmt.Fprintf(w, "data: %s\n\n", data)
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// serveGames returns the current snapshot of every game, for scripts
+func (s *Spectator) serveGames(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	numbers := make([]int, 0, len(s.games))
+	for number := range s.games {
+		numbers = append(numbers, number)
+	}
+	events := s.snapshots(numbers)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, "[%s]\n", bytes.Join(events, []byte(",")))
+}
+
+func (s *Spectator) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	spectatorTemplate.Execute(w, struct {
+		Colors        []string
+		Wall, Blocked string
+	}{PlayerColors, wallColor, blockedColor})
+}
+
+var spectatorTemplate = template.Must(template.New("spectator").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>LLM Snakes · live</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 1.5em; color: #222; }
+h1 { margin: 0 0 0.5em; }
+#layout { display: flex; gap: 2em; align-items: flex-start; }
+#games { list-style: none; padding: 0; margin: 0; min-width: 14em; max-height: 85vh; overflow-y: auto; }
+#games li { padding: 4px 8px; cursor: pointer; border-radius: 4px; }
+#games li.selected { background: #e8eefc; }
+#games li .state { color: #666; font-size: 0.85em; }
+#games li.live .state { color: #3cb44b; }
+#status { font-weight: bold; min-height: 1.4em; }
+#board { display: grid; gap: 1px; background: #ccc; border: 1px solid #ccc; width: max-content; margin: 0.5em 0 1em; }
+#board div { width: 24px; height: 24px; background: #fff; font: bold 13px monospace; display: flex; align-items: center; justify-content: center; color: #fff; }
+.player { border-left: 6px solid; padding: 0.2em 0.8em; margin-bottom: 1em; }
+.player.out { opacity: 0.5; }
+.player details { margin-top: 0.3em; }
+.player pre { white-space: pre-wrap; background: #f7f7f7; padding: 0.6em; margin: 0.3em 0; max-height: 20em; overflow-y: auto; }
+.rejected { color: #b00; }
+#conn { color: #666; font-size: 0.85em; }
+</style>
+</head>
+<body>
+<h1>LLM Snakes · live <span id="conn"></span></h1>
+<div id="layout">
+<ul id="games"></ul>
+<div id="game">
+<div id="status">Waiting for a game…</div>
+<div id="board"></div>
+<div id="players"></div>
+</div>
+</div>
+
+<script>
+const COLORS = {{.Colors}};
+const WALL = {{.Wall}}, BLOCKED = {{.Blocked}};
+const IDS = ["1", "2", "3", "4", "5", "6", "7", "8", "9", "A"];
+const ARROWS = { up: "↑", down: "↓", left: "←", right: "→", "up-left": "↖", "up-right": "↗", "down-left": "↙", "down-right": "↘" };
+const $ = (id) => document.getElementById(id);
+const games = {};
+let selected = null, follow = true;
+
+function listGames() {
+	const list = $("games");
+	list.innerHTML = "";
+	Object.values(games).sort((a, b) => b.number - a.number).forEach((g) => {
+		const li = document.createElement("li");
+		li.className = (g.number === selected ? "selected " : "") + (g.done ? "" : "live");
+		li.innerHTML = "<b>Game " + g.number + "</b><br><span class=state></span>";
+		li.querySelector(".state").textContent = (g.done ? g.status : "live · move " + g.moves) + " · " +
+			g.players.map((p) => p.model).join(" vs ");
+		li.onclick = () => { selected = g.number; follow = !g.done; listGames(); showGame(); };
+		list.appendChild(li);
+	});
+}
+
+function showGame() {
+	const g = games[selected];
+	if (!g) return;
+	$("status").textContent = "Game " + g.number + " · move " + g.moves + " · " + g.status;
+
+	const board = $("board");
+	board.style.gridTemplateColumns = "repeat(" + g.width + ", 24px)";
+	// Hex rows shift half a cell right of the row above
+	board.style.paddingRight = g.hex ? (g.height - 1) * 12.5 + "px" : "";
+	board.style.background = g.hex ? "none" : "";
+	board.innerHTML = "";
+	g.board.forEach((row, r) => [...row].forEach((code, c) => {
+		const d = document.createElement("div");
+		if (g.hex) {
+			d.style.transform = "translateX(" + r * 12.5 + "px)";
+			d.style.borderRadius = "40%";
+			d.style.outline = "1px solid #ccc";
+		}
+		const head = IDS.indexOf(code), trail = code.charCodeAt(0) - 97;
+		if (head >= 0) {
+			d.style.background = COLORS[head % COLORS.length];
+			d.textContent = g.last && g.last[0] === r && g.last[1] === c ? ARROWS[g.lastDir] || code : code;
+			if (!g.players[head].alive) d.style.opacity = "0.5";
+		} else if (code === "#") {
+			d.style.background = WALL;
+		} else if (code === "x") {
+			d.style.background = BLOCKED;
+		} else if (code !== ".") {
+			d.style.background = COLORS[trail % COLORS.length];
+			d.style.opacity = "0.45";
+		}
+		board.appendChild(d);
+	}));
+
+	const players = $("players");
+	const open = {};
+	players.querySelectorAll("details").forEach((d) => { open[d.dataset.key] = d.open; });
+	players.innerHTML = "";
+	g.players.forEach((p, i) => {
+		const div = document.createElement("div");
+		div.className = "player" + (p.alive ? "" : " out");
+		div.style.borderColor = COLORS[i % COLORS.length];
+		div.innerHTML = "<b></b> <span class=model></span><div class=meta></div>" +
+			"<details data-key=prompt-" + i + "><summary>Latest prompt</summary><pre class=prompt></pre></details>" +
+			"<details data-key=response-" + i + " open><summary>Latest response</summary><pre class=response></pre><div class=rejected></div></details>" +
+			"<details data-key=reasoning-" + i + " open hidden><summary>Reasoning</summary><pre class=reasoning></pre></details>";
+		div.querySelector("b").textContent = "Player " + p.id + (g.turn === p.id ? " · thinking…" : "");
+		div.querySelector(".model").textContent = p.model;
+		div.querySelector(".meta").textContent = (p.alive ? "alive" : "out") + " · " + p.moves + " moves" +
+			(p.latency ? " · last answer in " + p.latency.toFixed(2) + "s" : "");
+		div.querySelector(".prompt").textContent = p.prompt || "(none yet)";
+		div.querySelector(".response").textContent = p.response || "(none yet)";
+		div.querySelector(".rejected").textContent = p.rejected ? "Rejected: " + p.rejected : "";
+		div.querySelector(".reasoning").textContent = p.reasoning || "";
+		div.querySelector("[data-key^=reasoning]").hidden = !p.reasoning;
+		div.querySelectorAll("details").forEach((d) => {
+			if (d.dataset.key in open) d.open = open[d.dataset.key];
+		});
+		players.appendChild(div);
+	});
+}
+
+function connect() {
+	const events = new EventSource("events");
+	events.onopen = () => { $("conn").textContent = "· connected"; };
+	events.onerror = () => { $("conn").textContent = "· reconnecting…"; };
+	events.onmessage = (e) => {
+		const g = JSON.parse(e.data);
+		games[g.number] = g;
+		// Follow the newest live game unless the viewer picked a finished one
+		if (selected === null || (follow && !g.done && g.number > selected)) selected = g.number;
+		listGames();
+		if (g.number === selected) showGame();
+	};
+}
+connect();
+</script>
+</body>
+</html>
+`))
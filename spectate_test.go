@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSpectatorMoveEvent(t *testing.T) {
+	s := &Spectator{
+		games:       make(map[int]*SpectatorGame),
+		current:     make(map[*GameState]*SpectatorGame),
+		subscribers: make(map[*spectatorSubscriber]bool),
+	}
+	setup := &GameSetup{
+		Width: 5, Height: 5,
+		StartPositions: []Position{{0, 0}, {4, 4}},
+		Players:        []*PlayerConfig{{Model: "a"}, {Model: "b"}},
+	}
+	game := InitGame(setup)
+	s.GameStarted(game, 1)
+	s.Prompted(game, "1", "Your move")
+	MakeMove(game, "1", Right)
+	move := game.Moves[0]
+	move.Response, move.Reasoning = "Right is open.\nMOVE: right", "Right is open."
+	s.Moved(game, &move)
+
+	var view SpectatorGame
+	if err := json.Unmarshal(s.snapshots([]int{1})[0], &view); err != nil {
+		t.Fatal(err)
+	}
+	p := view.Players[0]
+	if p.Reasoning != "Right is open." || p.Response != move.Response || p.Moves != 1 {
+		t.Errorf("move event has player %+v", p)
+	}
+	if view.Last == nil || *view.Last != [2]int{0, 1} || view.LastDir != Right {
+		t.Errorf("move event has last move %v %s, want (0,1) right", view.Last, view.LastDir)
+	}
+
+	// The next prompt clears the previous answer
+	s.Prompted(game, "1", "Your move again")
+	if p := s.games[1].Players[0]; p.Reasoning != "" || p.Response != "" {
+		t.Errorf("a new prompt kept the previous answer: %+v", p)
+	}
+}
@@ -0,0 +1,580 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// migrations holds the schema changes of the results database in order.
+// The database records how many have been applied in PRAGMA user_version,
+// so existing files are upgraded in place. Never edit an applied migration;
+// append a new one instead.
+var migrations = []string{
+	// 1: games, their players and every move
+	`CREATE TABLE games (
+		id             INTEGER PRIMARY KEY AUTOINCREMENT,
+		run_id         TEXT    NOT NULL,
+		game_number    INTEGER NOT NULL,
+		started_at     TEXT    NOT NULL,
+		duration       REAL    NOT NULL,
+		seed           INTEGER NOT NULL,
+		size           INTEGER NOT NULL,
+		num_players    INTEGER NOT NULL,
+		prompt_version TEXT    NOT NULL,
+		winner         TEXT    NOT NULL, -- player ID, '' for a draw, 'error' if aborted
+		winner_model   TEXT,
+		total_moves    INTEGER NOT NULL,
+		error          TEXT
+	);
+	CREATE TABLE players (
+		game_id       INTEGER NOT NULL REFERENCES games(id),
+		player        TEXT    NOT NULL,
+		model         TEXT    NOT NULL,
+		temperature   REAL    NOT NULL,
+		start_row     INTEGER NOT NULL,
+		start_col     INTEGER NOT NULL,
+		eliminated_at INTEGER, -- move number, NULL if never eliminated
+		won           INTEGER NOT NULL,
+		PRIMARY KEY (game_id, player)
+	);
+	CREATE TABLE moves (
+		game_id     INTEGER NOT NULL REFERENCES games(id),
+		move_number INTEGER NOT NULL,
+		player      TEXT    NOT NULL,
+		model       TEXT    NOT NULL,
+		direction   TEXT    NOT NULL,
+		from_row    INTEGER NOT NULL,
+		from_col    INTEGER NOT NULL,
+		to_row      INTEGER NOT NULL,
+		to_col      INTEGER NOT NULL,
+		prompt_hash TEXT    NOT NULL,
+		response    TEXT    NOT NULL,
+		latency     REAL    NOT NULL,
+		retries     INTEGER NOT NULL,
+		PRIMARY KEY (game_id, move_number)
+	);
+	CREATE INDEX players_model ON players(model);`,
+
+	// 2: where the chosen move stood in the engine's ranking
+	`ALTER TABLE moves ADD COLUMN engine_rank INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE moves ADD COLUMN num_options INTEGER NOT NULL DEFAULT 0;`,
+
+	// 3: safety ratings of the chosen and the top-ranked move
+	`ALTER TABLE moves ADD COLUMN safety TEXT NOT NULL DEFAULT '';
+	ALTER TABLE moves ADD COLUMN best_safety TEXT NOT NULL DEFAULT '';`,
+
+	// 4: prompt options of each player, '' for the default prompt
+	`ALTER TABLE players ADD COLUMN variant TEXT NOT NULL DEFAULT '';`,
+
+	// 5: reasoning before the final answer in reasoning mode
+	`ALTER TABLE moves ADD COLUMN reasoning TEXT NOT NULL DEFAULT '';`,
+
+	// 6: tool calls made before each move in agent mode
+	`CREATE TABLE tool_calls (
+		game_id     INTEGER NOT NULL REFERENCES games(id),
+		move_number INTEGER NOT NULL,
+		call_number INTEGER NOT NULL,
+		name        TEXT    NOT NULL,
+		arguments   TEXT    NOT NULL,
+		result      TEXT    NOT NULL,
+		PRIMARY KEY (game_id, move_number, call_number)
+	);`,
+
+	// 7: retries caused by ambiguous responses
+	`ALTER TABLE moves ADD COLUMN ambiguous INTEGER NOT NULL DEFAULT 0;`,
+
+	// 8: every response to a move prompt, rejected ones included
+	`CREATE TABLE attempts (
+		game_id     INTEGER NOT NULL REFERENCES games(id),
+		move_number INTEGER NOT NULL,
+		attempt     INTEGER NOT NULL,
+		response    TEXT    NOT NULL,
+		failure     TEXT    NOT NULL, -- 'unparseable', 'ambiguous' or 'illegal'; '' if accepted
+		error       TEXT    NOT NULL,
+		latency     REAL    NOT NULL,
+		PRIMARY KEY (game_id, move_number, attempt)
+	);`,
+
+	// 9: rectangular boards; size stays the side of a square board, 0 otherwise
+	`ALTER TABLE games ADD COLUMN width INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE games ADD COLUMN height INTEGER NOT NULL DEFAULT 0;
+	UPDATE games SET width = size, height = size;`,
+
+	// 10: arena layouts and generated obstacles
+	`ALTER TABLE games ADD COLUMN arena TEXT NOT NULL DEFAULT '';
+	CREATE TABLE obstacles (
+		game_id  INTEGER NOT NULL REFERENCES games(id),
+		cell_row INTEGER NOT NULL,
+		cell_col INTEGER NOT NULL,
+		kind     TEXT    NOT NULL, -- 'wall' or 'blocked'
+		PRIMARY KEY (game_id, cell_row, cell_col)
+	);`,
+
+	// 11: board topology, 'bounded' or 'torus'
+	`ALTER TABLE games ADD COLUMN topology TEXT NOT NULL DEFAULT 'bounded';`,
+
+	// 12: cell geometry, 'square' or 'hex'
+	`ALTER TABLE games ADD COLUMN geometry TEXT NOT NULL DEFAULT 'square';`,
+
+	// 13: prompt template version of each seat, '' for games stored before,
+	// which only have the versions of the game
+	`ALTER TABLE players ADD COLUMN prompt_version TEXT NOT NULL DEFAULT '';`,
+}
+
+// Store persists game records in a local SQLite database
+type Store struct {
+	db *sql.DB
+}
+
+// OpenStore opens (or creates) the results database at path and brings its
+// schema up to date
+func OpenStore(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &Store{db: db}
+	if err := store.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this program supports (%d)", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteGame stores a finished game with its players and moves
+func (s *Store) WriteGame(runID string, record *GameRecord) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	winnerModel := sql.NullString{}
+	if idx := playerIndex(record.Winner); idx >= 0 {
+		winnerModel = sql.NullString{String: record.Setup.Players[idx].Model, Valid: true}
+	}
+
+	squareSize := 0
+	if record.Width == record.Height {
+		squareSize = record.Width
+	}
+
+	res, err := tx.Exec(`INSERT INTO games
+		(run_id, game_number, started_at, duration, seed, size, width, height, topology, geometry, arena, num_players,
+		 prompt_version, winner, winner_model, total_moves, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		runID, record.Number, record.StartedAt.Format(time.RFC3339), record.Duration, record.Setup.Seed,
+		squareSize, record.Width, record.Height, record.Setup.Topology, record.Setup.Geometry, record.Setup.Arena, len(record.Setup.Players),
+		record.PromptVersion, record.Winner, winnerModel, len(record.Moves), record.Error)
+	if err != nil {
+		return err
+	}
+	gameID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	obstacles := map[string][]Position{"wall": record.Setup.Walls, "blocked": record.Setup.Blocked}
+	for kind, cells := range obstacles {
+		for _, pos := range cells {
+			_, err := tx.Exec(`INSERT INTO obstacles (game_id, cell_row, cell_col, kind) VALUES (?, ?, ?, ?)`,
+				gameID, pos.Row, pos.Col, kind)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, player := range record.Setup.Players {
+		playerID := PlayerIDs[i]
+		pos := record.Setup.StartPositions[i]
+
+		eliminatedAt := sql.NullInt64{}
+		if moveNumber, ok := record.EliminatedAt[playerID]; ok {
+			eliminatedAt = sql.NullInt64{Int64: int64(moveNumber), Valid: true}
+		}
+
+		_, err := tx.Exec(`INSERT INTO players
+			(game_id, player, model, temperature, variant, prompt_version, start_row, start_col, eliminated_at, won)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			gameID, playerID, player.Model, player.Temperature, player.Variant, player.PromptVersion(), pos.Row, pos.Col,
+			eliminatedAt, record.Winner == playerID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, move := range record.Moves {
+		_, err := tx.Exec(`INSERT INTO moves
+			(game_id, move_number, player, model, direction, from_row, from_col, to_row, to_col,
+			 prompt_hash, response, latency, retries, ambiguous, engine_rank, num_options, safety, best_safety, reasoning)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			gameID, i+1, move.Player, record.Setup.Players[playerIndex(move.Player)].Model, move.Direction,
+			move.From.Row, move.From.Col, move.To.Row, move.To.Col,
+			move.PromptHash, move.Response, move.Latency, move.Retries, move.Ambiguous, move.EngineRank, move.NumOptions,
+			move.SafetyLevel, move.BestSafety, move.Reasoning)
+		if err != nil {
+			return err
+		}
+
+		for j, call := range move.ToolCalls {
+			_, err := tx.Exec(`INSERT INTO tool_calls
+				(game_id, move_number, call_number, name, arguments, result)
+				VALUES (?, ?, ?, ?, ?, ?)`,
+				gameID, i+1, j+1, call.Name, call.Arguments, call.Result)
+			if err != nil {
+				return err
+			}
+		}
+
+		for j, attempt := range move.Attempts {
+			_, err := tx.Exec(`INSERT INTO attempts
+				(game_id, move_number, attempt, response, failure, error, latency)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				gameID, i+1, j+1, attempt.Response, attempt.Failure, attempt.Error, attempt.Latency)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// statsGroupColumns maps the dimensions accepted by `stats -by` to columns
+var statsGroupColumns = map[string]string{
+	"model":    "p.model",
+	"size":     "g.width || 'x' || g.height",
+	"arena":    "g.arena",
+	"topology": "g.topology",
+	"geometry": "g.geometry",
+	"prompt":   "COALESCE(NULLIF(p.prompt_version, ''), g.prompt_version)",
+	"players":  "g.num_players",
+	"seat":     "p.player",
+	"variant":  "p.variant",
+	"run":      "g.run_id",
+}
+
+// RunStatsCommand implements the `stats` subcommand, which summarizes stored
+// results without playing any games
+func RunStatsCommand(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	path := fs.String("db", "snakes.db", "SQLite database written with -db")
+	by := fs.String("by", "model", "Comma-separated grouping: model, variant, size, topology, geometry, arena, prompt, players, seat, run")
+	run := fs.String("run", "", "Only include games from this run ID")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(*path); err != nil {
+		return fmt.Errorf("database %s: %w", *path, err)
+	}
+	store, err := OpenStore(*path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var groups, headers []string
+	for _, key := range strings.Split(*by, ",") {
+		key = strings.TrimSpace(key)
+		column, ok := statsGroupColumns[key]
+		if !ok {
+			return fmt.Errorf("unknown grouping %q", key)
+		}
+		groups = append(groups, column)
+		headers = append(headers, strings.ToUpper(key))
+	}
+	groupExpr := strings.Join(groups, ", ")
+
+	query := `WITH player_moves AS (
+			SELECT game_id, player, COUNT(*) AS moves, AVG(latency) AS latency, SUM(retries) AS retries,
+				SUM(ambiguous) AS ambiguous,
+				SUM(num_options > 1) AS unforced, SUM(num_options > 1 AND engine_rank = 1) AS followed,
+				SUM(safety = 'DEATH TRAP' AND best_safety NOT IN ('', 'DEATH TRAP')) AS traps
+			FROM moves GROUP BY game_id, player
+		)
+		SELECT ` + groupExpr + `, COUNT(*), SUM(p.won), SUM(g.winner = 'error'),
+			AVG(COALESCE(pm.moves, 0)), COALESCE(AVG(pm.latency), 0), COALESCE(SUM(pm.retries), 0),
+			COALESCE(SUM(pm.ambiguous), 0),
+			COALESCE(SUM(pm.unforced), 0), COALESCE(SUM(pm.followed), 0), COALESCE(SUM(pm.traps), 0)
+		FROM players p
+		JOIN games g ON g.id = p.game_id
+		LEFT JOIN player_moves pm ON pm.game_id = p.game_id AND pm.player = p.player
+		WHERE ? = '' OR g.run_id = ?
+		GROUP BY ` + groupExpr + `
+		ORDER BY ` + groupExpr
+
+	rows, err := store.db.Query(query, *run, *run)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(headers, "\t")+"\tGAMES\tWINS\tWIN RATE\t95% CI\tERRORS\tAVG MOVES\tAVG LATENCY\tRETRIES\tAMBIGUOUS\tTOP MOVE\tTRAPS")
+
+	for rows.Next() {
+		keys := make([]any, len(groups))
+		for i := range keys {
+			keys[i] = new(any)
+		}
+		var games, wins, errors, retries, ambiguous, unforced, followed, traps int
+		var avgMoves, avgLatency float64
+		dest := append(keys, &games, &wins, &errors, &avgMoves, &avgLatency, &retries, &ambiguous, &unforced, &followed, &traps)
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+
+		cells := make([]string, len(keys))
+		for i, key := range keys {
+			cells[i] = fmt.Sprint(*key.(*any))
+		}
+		lo, hi := WilsonInterval(wins, games)
+		followRate := 0.0
+		if unforced > 0 {
+			followRate = float64(followed) / float64(unforced) * 100
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f%%\t%.1f-%.1f%%\t%d\t%.1f\t%.2fs\t%d\t%d\t%.1f%%\t%d\n",
+			strings.Join(cells, "\t"), games, wins, float64(wins)/float64(games)*100,
+			lo*100, hi*100, errors, avgMoves, avgLatency, retries, ambiguous, followRate, traps)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// StoredGame is a game record loaded back from the database
+type StoredGame struct {
+	ID     int64
+	RunID  string
+	Record *GameRecord
+}
+
+// LoadGames reads every stored game, optionally restricted to one run, in
+// the order they were played
+func (s *Store) LoadGames(runID string) ([]*StoredGame, error) {
+	// The rows of the other tables are restricted to the selected games
+	// with this join, which takes the run ID twice like the games query
+	const runGamesJoin = `JOIN games g ON g.id = t.game_id WHERE ? = '' OR g.run_id = ?`
+
+	rows, err := s.db.Query(`SELECT id, run_id, game_number, started_at, duration, seed, width, height, topology, geometry, arena,
+			prompt_version, winner, COALESCE(error, '')
+		FROM games WHERE ? = '' OR run_id = ? ORDER BY id`, runID, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	var games []*StoredGame
+	byID := make(map[int64]*StoredGame)
+	for rows.Next() {
+		record := &GameRecord{Setup: &GameSetup{}, EliminatedAt: make(map[string]int)}
+		game := &StoredGame{Record: record}
+		var startedAt string
+		err := rows.Scan(&game.ID, &game.RunID, &record.Number, &startedAt, &record.Duration,
+			&record.Setup.Seed, &record.Width, &record.Height, &record.Setup.Topology, &record.Setup.Geometry, &record.Setup.Arena, &record.PromptVersion,
+			&record.Winner, &record.Error)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		record.StartedAt, _ = time.Parse(time.RFC3339, startedAt)
+		record.Setup.Width, record.Setup.Height = record.Width, record.Height
+		games = append(games, game)
+		byID[game.ID] = game
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Players are stored per seat; player IDs sort in seat order
+	rows, err = s.db.Query(`SELECT t.game_id, t.player, t.model, t.temperature, t.variant, t.prompt_version,
+			t.start_row, t.start_col, t.eliminated_at
+		FROM players t `+runGamesJoin+` ORDER BY t.game_id, t.player`, runID, runID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var gameID int64
+		var player string
+		config := &PlayerConfig{}
+		var pos Position
+		var eliminatedAt sql.NullInt64
+		var promptVersion string
+		err := rows.Scan(&gameID, &player, &config.Model, &config.Temperature, &config.Variant, &promptVersion,
+			&pos.Row, &pos.Col, &eliminatedAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		game, ok := byID[gameID]
+		if !ok {
+			continue
+		}
+		// Games stored before seats had their own version only have one if
+		// all seats shared it. A stored template carries only its version.
+		if promptVersion == "" && !strings.Contains(game.Record.PromptVersion, ",") {
+			promptVersion = game.Record.PromptVersion
+		}
+		config.Prompt = &PromptTemplate{Version: promptVersion}
+		setup := game.Record.Setup
+		setup.Players = append(setup.Players, config)
+		setup.StartPositions = append(setup.StartPositions, pos)
+		if eliminatedAt.Valid {
+			game.Record.EliminatedAt[player] = int(eliminatedAt.Int64)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(`SELECT t.game_id, t.cell_row, t.cell_col, t.kind
+		FROM obstacles t `+runGamesJoin+` ORDER BY t.game_id, t.cell_row, t.cell_col`, runID, runID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var gameID int64
+		var pos Position
+		var kind string
+		if err := rows.Scan(&gameID, &pos.Row, &pos.Col, &kind); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		game, ok := byID[gameID]
+		if !ok {
+			continue
+		}
+		setup := game.Record.Setup
+		if kind == "blocked" {
+			setup.Blocked = append(setup.Blocked, pos)
+		} else {
+			setup.Walls = append(setup.Walls, pos)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(`SELECT t.game_id, t.player, t.direction, t.from_row, t.from_col, t.to_row, t.to_col,
+			t.prompt_hash, t.response, t.latency, t.retries, t.ambiguous, t.engine_rank, t.num_options, t.safety,
+			t.best_safety, t.reasoning
+		FROM moves t `+runGamesJoin+` ORDER BY t.game_id, t.move_number`, runID, runID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var gameID int64
+		var move Move
+		err := rows.Scan(&gameID, &move.Player, &move.Direction, &move.From.Row, &move.From.Col,
+			&move.To.Row, &move.To.Col, &move.PromptHash, &move.Response, &move.Latency,
+			&move.Retries, &move.Ambiguous, &move.EngineRank, &move.NumOptions, &move.SafetyLevel, &move.BestSafety,
+			&move.Reasoning)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if game, ok := byID[gameID]; ok {
+			game.Record.Moves = append(game.Record.Moves, move)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(`SELECT t.game_id, t.move_number, t.name, t.arguments, t.result
+		FROM tool_calls t `+runGamesJoin+` ORDER BY t.game_id, t.move_number, t.call_number`, runID, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var gameID int64
+		var moveNumber int
+		var call ToolUse
+		if err := rows.Scan(&gameID, &moveNumber, &call.Name, &call.Arguments, &call.Result); err != nil {
+			return nil, err
+		}
+		game, ok := byID[gameID]
+		if !ok || moveNumber < 1 || moveNumber > len(game.Record.Moves) {
+			continue
+		}
+		move := &game.Record.Moves[moveNumber-1]
+		move.ToolCalls = append(move.ToolCalls, call)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(`SELECT t.game_id, t.move_number, t.response, t.failure, t.error, t.latency
+		FROM attempts t `+runGamesJoin+` ORDER BY t.game_id, t.move_number, t.attempt`, runID, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var gameID int64
+		var moveNumber int
+		var attempt Attempt
+		if err := rows.Scan(&gameID, &moveNumber, &attempt.Response, &attempt.Failure, &attempt.Error, &attempt.Latency); err != nil {
+			return nil, err
+		}
+		game, ok := byID[gameID]
+		if !ok || moveNumber < 1 || moveNumber > len(game.Record.Moves) {
+			continue
+		}
+		move := &game.Record.Moves[moveNumber-1]
+		move.Attempts = append(move.Attempts, attempt)
+	}
+
+	return games, rows.Err()
+}
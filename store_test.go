@@ -0,0 +1,207 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testRecord is a short finished hex game on a torus with obstacles, a
+// rejected response and a tool call
+func testRecord(number int) *GameRecord {
+	setup := &GameSetup{
+		Seed:           42,
+		Width:          5,
+		Height:         4,
+		Topology:       TopologyTorus,
+		Geometry:       GeometryHex,
+		Arena:          "obstacles=0.1",
+		Walls:          []Position{{0, 4}},
+		Blocked:        []Position{{3, 0}},
+		StartPositions: []Position{{1, 1}, {2, 3}},
+		Players: []*PlayerConfig{
+			{Model: "llama3.2", Temperature: 0.7},
+			{Model: "mistral", Temperature: 0.2, Variant: "agent"},
+		},
+	}
+	return &GameRecord{
+		Number:        number,
+		StartedAt:     time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		Duration:      1.5,
+		Setup:         setup,
+		Width:         setup.Width,
+		Height:        setup.Height,
+		PromptVersion: setup.PromptVersion(),
+		Winner:        "1",
+		Moves: []Move{
+			{
+				Player: "1", Direction: Right, From: Position{1, 1}, To: Position{1, 2},
+				PromptHash: "abc", Response: "right", Latency: 0.25, Retries: 1, Ambiguous: 1,
+				EngineRank: 1, NumOptions: 6, SafetyLevel: "GOOD", BestSafety: "GOOD",
+				Attempts: []Attempt{
+					{Response: "up or right", Failure: FailAmbiguous, Error: "ambiguous response: names up and right", Latency: 0.1},
+					{Response: "right", Latency: 0.15},
+				},
+			},
+			{
+				Player: "2", Direction: DownLeft, From: Position{2, 3}, To: Position{3, 2},
+				PromptHash: "def", Response: `make_move({"direction":"down-left"})`, Latency: 0.5,
+				EngineRank: 2, NumOptions: 5, SafetyLevel: "RISKY", BestSafety: "GOOD",
+				ToolCalls: []ToolUse{
+					{Name: ToolLegalMoves, Arguments: "{}", Result: "left, down-left"},
+					{Name: ToolMakeMove, Arguments: `{"direction":"down-left"}`, Result: "moved down-left"},
+				},
+				Attempts: []Attempt{{Response: `make_move({"direction":"down-left"})`, Latency: 0.5}},
+			},
+		},
+		EliminatedAt: map[string]int{"2": 3},
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	store, err := OpenStore(filepath.Join(t.TempDir(), "snakes.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	want := testRecord(1)
+	if err := store.WriteGame("run-a", testRecord(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.WriteGame("run-b", want); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := store.LoadGames("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("LoadGames(\"\") returned %d games, want 2", len(all))
+	}
+
+	games, err := store.LoadGames("run-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 1 {
+		t.Fatalf("LoadGames(\"run-b\") returned %d games, want 1", len(games))
+	}
+	if games[0].RunID != "run-b" {
+		t.Errorf("run ID = %q, want run-b", games[0].RunID)
+	}
+
+	got := games[0].Record
+	if !got.StartedAt.Equal(want.StartedAt) {
+		t.Errorf("StartedAt = %v, want %v", got.StartedAt, want.StartedAt)
+	}
+	if got.Number != want.Number || got.Duration != want.Duration || got.Winner != want.Winner ||
+		got.Width != want.Width || got.Height != want.Height || got.PromptVersion != want.PromptVersion {
+		t.Errorf("game = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(got.Moves, want.Moves) {
+		t.Errorf("moves = %+v, want %+v", got.Moves, want.Moves)
+	}
+	if !reflect.DeepEqual(got.EliminatedAt, want.EliminatedAt) {
+		t.Errorf("eliminations = %v, want %v", got.EliminatedAt, want.EliminatedAt)
+	}
+
+	gs, ws := got.Setup, want.Setup
+	if gs.Seed != ws.Seed || gs.Topology != ws.Topology || gs.Geometry != ws.Geometry || gs.Arena != ws.Arena {
+		t.Errorf("setup = %+v, want %+v", gs, ws)
+	}
+	if !reflect.DeepEqual(gs.Walls, ws.Walls) || !reflect.DeepEqual(gs.Blocked, ws.Blocked) ||
+		!reflect.DeepEqual(gs.StartPositions, ws.StartPositions) {
+		t.Errorf("cells = %v %v %v, want %v %v %v", gs.Walls, gs.Blocked, gs.StartPositions, ws.Walls, ws.Blocked, ws.StartPositions)
+	}
+	if len(gs.Players) != len(ws.Players) {
+		t.Fatalf("%d players, want %d", len(gs.Players), len(ws.Players))
+	}
+	for i, player := range gs.Players {
+		w := ws.Players[i]
+		if player.Model != w.Model || player.Temperature != w.Temperature || player.Variant != w.Variant ||
+			player.PromptVersion() != w.PromptVersion() {
+			t.Errorf("player %d = %s %g %q %s, want %s %g %q %s", i, player.Model, player.Temperature, player.Variant,
+				player.PromptVersion(), w.Model, w.Temperature, w.Variant, w.PromptVersion())
+		}
+	}
+}
+
+func TestStoreMigratesOldDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+
+	// A database written before boards could be rectangular
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(migrations[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("PRAGMA user_version = 1"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`INSERT INTO games (run_id, game_number, started_at, duration, seed, size, num_players,
+			prompt_version, winner, total_moves)
+		VALUES ('old', 1, '2026-01-02T03:04:05Z', 2.5, 7, 8, 2, 'v1', '2', 0)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, model := range []string{"llama3.2", "mistral"} {
+		_, err := db.Exec(`INSERT INTO players (game_id, player, model, temperature, start_row, start_col, won)
+			VALUES (1, ?, ?, 0.7, ?, 0, ?)`, PlayerIDs[i], model, i, i == 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	var version int
+	if err := store.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != len(migrations) {
+		t.Errorf("schema version %d after migrating, want %d", version, len(migrations))
+	}
+
+	games, err := store.LoadGames("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 1 {
+		t.Fatalf("loaded %d games, want 1", len(games))
+	}
+	record := games[0].Record
+	setup := record.Setup
+	if record.Winner != "2" || record.PromptVersion != "v1" || setup.Seed != 7 {
+		t.Errorf("game = %+v", record)
+	}
+	if setup.Topology != TopologyBounded || setup.Geometry != GeometrySquare {
+		t.Errorf("topology %q and geometry %q, want the defaults", setup.Topology, setup.Geometry)
+	}
+	if len(setup.Players) != 2 || setup.Players[1].Model != "mistral" || setup.Players[1].PromptVersion() != "v1" {
+		t.Errorf("players = %+v", setup.Players)
+	}
+
+	// A current database is left alone, and one from a newer program refused
+	store.Close()
+	if store, err = OpenStore(path); err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	if _, err := store.db.Exec("PRAGMA user_version = 99"); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+	if store, err = OpenStore(path); err == nil {
+		t.Error("opening a newer schema succeeded, want an error")
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Topology selects what lies beyond the edges of the board
+type Topology string
+
+const (
+	TopologyBounded Topology = "bounded" // The edges are walls
+	TopologyTorus   Topology = "torus"   // Moving off one edge enters the opposite edge
+)
+
+// Topologies lists all topologies, the default first
+var Topologies = []Topology{TopologyBounded, TopologyTorus}
+
+// ParseTopology validates a topology name
+func ParseTopology(name string) (Topology, error) {
+	for _, topology := range Topologies {
+		if Topology(strings.ToLower(name)) == topology {
+			return topology, nil
+		}
+	}
+	return "", fmt.Errorf("unknown topology %q (known: bounded, torus)", name)
+}
+
+// Wrap maps a position that has left a torus board back onto it. On a
+// bounded board positions are returned unchanged.
+func (g *GameState) Wrap(pos Position) Position {
+	if g.Topology != TopologyTorus {
+		return pos
+	}
+	pos.Row = (pos.Row%g.Height + g.Height) % g.Height
+	pos.Col = (pos.Col%g.Width + g.Width) % g.Width
+	return pos
+}
+
+// stepPosition moves one cell in a direction, ignoring the board. On a
+// hex board the diagonals follow axial coordinates (see Geometry).
+func stepPosition(pos Position, dir Direction) Position {
+	switch dir {
+	case Up, UpLeft:
+		pos.Row--
+	case Down, DownRight:
+		pos.Row++
+	case Left:
+		pos.Col--
+	case Right:
+		pos.Col++
+	case UpRight:
+		pos.Row--
+		pos.Col++
+	case DownLeft:
+		pos.Row++
+		pos.Col--
+	}
+	return pos
+}
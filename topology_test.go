@@ -0,0 +1,93 @@
+package main
+
+import "testing"
+
+func TestTorusWrap(t *testing.T) {
+	torus := &GameState{Width: 4, Height: 3, Topology: TopologyTorus}
+	bounded := &GameState{Width: 4, Height: 3, Topology: TopologyBounded}
+	tests := []struct {
+		game    *GameState
+		pos     Position
+		want    Position
+		inBoard bool
+	}{
+		{torus, Position{1, 2}, Position{1, 2}, true},
+		{torus, Position{-1, 0}, Position{2, 0}, true},
+		{torus, Position{3, 0}, Position{0, 0}, true},
+		{torus, Position{0, -1}, Position{0, 3}, true},
+		{torus, Position{0, 4}, Position{0, 0}, true},
+		{torus, Position{-1, 4}, Position{2, 0}, true},
+		{torus, Position{-7, -9}, Position{2, 3}, true},
+		{bounded, Position{-1, 0}, Position{-1, 0}, false},
+		{bounded, Position{0, 4}, Position{0, 4}, false},
+	}
+	for _, tt := range tests {
+		got := tt.game.Wrap(tt.pos)
+		if got != tt.want {
+			t.Errorf("%s Wrap(%v) = %v, want %v", tt.game.Topology, tt.pos, got, tt.want)
+		}
+		if tt.game.InBounds(got) != tt.inBoard {
+			t.Errorf("%s Wrap(%v) = %v is on the board: %v, want %v", tt.game.Topology, tt.pos, got, !tt.inBoard, tt.inBoard)
+		}
+	}
+}
+
+func TestBlockedMovesAcrossTheEdge(t *testing.T) {
+	// Player 1 sits in the top left corner. On a torus, up leads to the wall
+	// at the bottom, left to the blocked cell at the right edge, and right
+	// into Player 2, who has just moved up:
+	//
+	//	1 2 . x
+	//	. b . .
+	//	# . . .
+	tests := []struct {
+		topology Topology
+		want     map[Direction]string
+	}{
+		{TopologyTorus, map[Direction]string{
+			Up:    "a wall across the edge",
+			Left:  "a blocked cell across the edge",
+			Right: "already visited",
+		}},
+		{TopologyBounded, map[Direction]string{
+			Up:    "out of bounds",
+			Left:  "out of bounds",
+			Right: "already visited",
+		}},
+	}
+	for _, tt := range tests {
+		game := InitGame(&GameSetup{
+			Width: 4, Height: 3, Topology: tt.topology,
+			Walls:          []Position{{2, 0}},
+			Blocked:        []Position{{0, 3}},
+			StartPositions: []Position{{0, 0}, {1, 1}},
+			Players:        []*PlayerConfig{{Model: "a"}, {Model: "b"}},
+		})
+		MakeMove(game, "2", Up)
+
+		validMoves := GetValidMoves(game, "1")
+		if len(validMoves) != 1 || validMoves[0] != Down {
+			t.Errorf("%s: valid moves %v, want [down]", tt.topology, validMoves)
+		}
+		blocked := getBlockedMoves(game, "1", validMoves)
+		if len(blocked) != len(tt.want) {
+			t.Errorf("%s: blocked moves %v, want %v", tt.topology, blocked, tt.want)
+		}
+		for dir, reason := range tt.want {
+			if blocked[dir] != reason {
+				t.Errorf("%s: %s is blocked as %q, want %q", tt.topology, dir, blocked[dir], reason)
+			}
+		}
+	}
+
+	// Moving down from the bottom row of a torus wraps onto the top row
+	game := InitGame(&GameSetup{
+		Width: 3, Height: 3, Topology: TopologyTorus,
+		StartPositions: []Position{{2, 1}, {0, 1}},
+		Players:        []*PlayerConfig{{Model: "a"}, {Model: "b"}},
+	})
+	validMoves := GetValidMoves(game, "1")
+	if reason := getBlockedMoves(game, "1", validMoves)[Down]; reason != "already visited across the edge" {
+		t.Errorf("down into Player 2 across the edge is blocked as %q", reason)
+	}
+}
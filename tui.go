@@ -0,0 +1,515 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// TUI settings
+const (
+	tuiLogLines       = 8  // Lines of other output shown under the board
+	tuiPanelWidth     = 72 // Visible width of the side panel
+	tuiReasoningLines = 3  // Lines of a player's last reasoning shown in the panel
+	tuiMinDelay       = 25 * time.Millisecond
+	tuiMaxDelay       = 5 * time.Second
+)
+
+// TUI redraws the game in place in an ANSI terminal: a colored board, a
+// side panel with the players, their last answers and the standings, and
+// keys to pause, step and change the speed. Events without a place on the
+// board are shown as a log under it.
+type TUI struct {
+	out    *os.File // The terminal
+	stty   string   // Terminal settings to restore, "" if keys are unavailable
+	signal chan os.Signal
+
+	mu        sync.Mutex
+	number    int // The game being shown
+	board     []string
+	panel     []string
+	standings []string
+	log       []string
+	paused    bool
+	step      bool
+	delay     time.Duration
+	wake      chan struct{}
+	closing   bool
+}
+
+// StartTUI switches the terminal to the full-screen view
+func StartTUI(delay time.Duration) *TUI {
+	t := &TUI{
+		out:   os.Stdout,
+		delay: delay,
+		wake:  make(chan struct{}, 1),
+	}
+
+	// Keys are read unbuffered; without a terminal the view still works
+	if state, err := stty("-g"); err == nil {
+		if _, err := stty("cbreak", "-echo"); err == nil {
+			t.stty = strings.TrimSpace(state)
+			go t.readKeys()
+		}
+	}
+
+	t.signal = make(chan os.Signal, 1)
+	signal.Notify(t.signal, os.Interrupt)
+	go func() {
+		if _, ok := <-t.signal; ok {
+			t.quit()
+		}
+	}()
+
+	t.out.WriteString("\x1b[?1049h\x1b[?25l")
+	return t
+}
+
+// stty runs stty on the terminal
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// Close restores the terminal
+func (t *TUI) Close() {
+	t.mu.Lock()
+	if t.closing {
+		t.mu.Unlock()
+		return
+	}
+	t.closing = true
+	t.mu.Unlock()
+
+	signal.Stop(t.signal)
+	close(t.signal)
+	t.out.WriteString("\x1b[?25h\x1b[?1049l")
+	if t.stty != "" {
+		stty(t.stty)
+	}
+}
+
+// quit ends the program from the q key or Ctrl-C. Games already finished
+// have been saved.
+func (t *TUI) quit() {
+	t.Close()
+	fmt.Println("Stopped.")
+	os.Exit(130)
+}
+
+// logLine adds a line to the log under the board
+func (t *TUI) logLine(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	t.mu.Lock()
+	t.log = append(t.log, line)
+	if len(t.log) > tuiLogLines {
+		t.log = t.log[len(t.log)-tuiLogLines:]
+	}
+	t.mu.Unlock()
+	t.render()
+}
+
+// readKeys handles space (pause), n (step while paused), + and - (speed)
+// and q (quit)
+func (t *TUI) readKeys() {
+	buf := make([]byte, 1)
+	for {
+		if n, err := os.Stdin.Read(buf); err != nil || n == 0 {
+			return
+		}
+		t.mu.Lock()
+		switch buf[0] {
+		case ' ', 'p':
+			t.paused = !t.paused
+		case 'n', 's':
+			t.step = true
+		case '+', '=', 'f':
+			t.delay = max(t.delay/2, tuiMinDelay)
+		case '-', '_':
+			t.delay = min(max(t.delay*2, tuiMinDelay), tuiMaxDelay)
+		case 'q':
+			t.mu.Unlock()
+			t.quit()
+			return
+		}
+		t.mu.Unlock()
+		t.render()
+		select {
+		case t.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Wait paces the game: it returns after the move delay, or when paused,
+// once the next step is requested or play resumes
+func (t *TUI) Wait() {
+	deadline := time.After(t.currentDelay())
+	for {
+		t.mu.Lock()
+		paused, step := t.paused, t.step
+		t.step = false
+		t.mu.Unlock()
+
+		switch {
+		case paused && step:
+			return
+		case paused:
+			<-t.wake
+		default:
+			select {
+			case <-deadline:
+				return
+			case <-t.wake:
+			}
+		}
+	}
+}
+
+func (t *TUI) currentDelay() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.delay
+}
+
+// draw redraws the board and side panel. The status line says what is
+// happening, e.g. whose turn it is.
+func (t *TUI) draw(game *GameState, status string) {
+	board := tuiBoard(game)
+	t.mu.Lock()
+	t.board, t.panel = board, tuiPanel(game, t.number, status)
+	t.mu.Unlock()
+	t.render()
+}
+
+// The TUI is a Renderer: it redraws after every move and paces the game,
+// and shows the other events in its log
+
+func (t *TUI) GameStarted(game *GameState, number int) {
+	t.mu.Lock()
+	t.number = number
+	t.mu.Unlock()
+	t.draw(game, "")
+	t.Wait()
+}
+
+func (t *TUI) Thinking(game *GameState, player string, turn int) {
+	t.draw(game, fmt.Sprintf("Player %s is thinking…", player))
+}
+
+func (t *TUI) Prompted(game *GameState, player string, prompt string) {}
+
+func (t *TUI) Rejected(game *GameState, player string, attempt Attempt, retry int) {
+	t.logLine(fmt.Sprintf("Player %s: %s response %q (%s)", player, attempt.Failure, attempt.Response, attempt.Error))
+}
+
+func (t *TUI) Moved(game *GameState, move *Move) {
+	t.draw(game, "")
+	t.Wait()
+}
+
+func (t *TUI) Eliminated(game *GameState, player string, turn int) {
+	t.logLine(fmt.Sprintf("❌ Player %s is eliminated (no valid moves)", player))
+}
+
+func (t *TUI) GameOver(game *GameState, record *GameRecord) {
+	status := "Draw!"
+	switch {
+	case record.Error != "":
+		status = "Error: " + record.Error
+	case record.Winner != "":
+		status = fmt.Sprintf("Player %s wins!", record.Winner)
+	}
+	t.draw(game, status)
+	t.Wait()
+}
+
+func (t *TUI) Stats(stats *GameStats) {
+	standings := tuiStandings(stats)
+	t.mu.Lock()
+	t.standings = standings
+	t.mu.Unlock()
+	t.render()
+}
+
+func (t *TUI) SetupResult(result *SetupResult) {
+	outcome := "split evenly"
+	if winner := result.Winner(); winner != "" {
+		outcome = winner + " wins"
+	}
+	t.logLine(fmt.Sprintf("Setup %d: %s (%d games)", result.Number, outcome, result.Games))
+}
+
+func (t *TUI) Warning(text string) {
+	t.logLine(text)
+}
+
+func (t *TUI) Debug(title, text string) {
+	if title != "" {
+		text = title + ": " + text
+	}
+	for _, line := range strings.Split(text, "\n") {
+		t.logLine(line)
+	}
+}
+
+// render writes the current frame over the previous one
+func (t *TUI) render() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closing {
+		return
+	}
+
+	state := fmt.Sprintf("playing · %v per move", t.delay)
+	if t.paused {
+		state = "\x1b[1;33mPAUSED\x1b[0m · n to step"
+	}
+	keys := "[space] pause  [n] step  [+/-] speed  [q] quit"
+	if t.stty == "" {
+		keys = "(keys unavailable: not a terminal)"
+	}
+	panel := append([]string(nil), t.panel...)
+	if len(t.standings) > 0 {
+		panel = append(append(panel, ""), t.standings...)
+	}
+	panel = append(panel, "", "\x1b[2m"+keys+"\x1b[0m", state)
+
+	boardWidth := 0
+	if len(t.board) > 0 {
+		boardWidth = visibleWidth(t.board[0])
+	}
+
+	var buf strings.Builder
+	buf.WriteString("\x1b[H")
+	for i := 0; i < max(len(t.board), len(panel)); i++ {
+		left := strings.Repeat(" ", boardWidth)
+		if i < len(t.board) {
+			left = t.board[i]
+		}
+		right := ""
+		if i < len(panel) {
+			right = panel[i]
+		}
+		buf.WriteString(left + "   " + right + "\x1b[K\n")
+	}
+	buf.WriteString("\x1b[K\n")
+	for _, line := range t.log {
+		buf.WriteString("\x1b[2m" + truncate(line, boardWidth+3+tuiPanelWidth) + "\x1b[0m\x1b[K\n")
+	}
+	buf.WriteString("\x1b[J")
+	t.out.WriteString(buf.String())
+}
+
+// ansiColor returns the escape sequence for a "#rrggbb" color, as
+// background or foreground, scaled in brightness
+func ansiColor(hex string, background bool, brightness float64) string {
+	value, _ := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	r := int(float64(value>>16&0xff) * brightness)
+	g := int(float64(value>>8&0xff) * brightness)
+	b := int(float64(value&0xff) * brightness)
+	layer := 38
+	if background {
+		layer = 48
+	}
+	return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", layer, r, g, b)
+}
+
+// tuiBoard draws the board two columns per cell: heads in the player's
+// color, trails in a darker shade. The head that moved last shows the
+// direction of its move. Rows of a hex board are shifted one column right
+// of the row above, and padded so all lines have the same width.
+func tuiBoard(game *GameState) []string {
+	arrows := map[Direction]string{Up: "↑", Down: "↓", Left: "←", Right: "→",
+		UpLeft: "↖", UpRight: "↗", DownLeft: "↙", DownRight: "↘"}
+	hex := game.Geometry == GeometryHex
+	shift := func(row int) (string, string) {
+		if !hex {
+			return "", ""
+		}
+		return strings.Repeat(" ", row), strings.Repeat(" ", game.Height-1-row)
+	}
+	var last *Move
+	if len(game.Moves) > 0 {
+		last = &game.Moves[len(game.Moves)-1]
+	}
+
+	header := "    "
+	for col := 0; col < game.Width; col++ {
+		header += fmt.Sprintf("%-2d", col%10)
+	}
+	_, pad := shift(0)
+	lines := []string{"\x1b[2m" + header + pad + "\x1b[0m"}
+
+	for row := 0; row < game.Height; row++ {
+		var line strings.Builder
+		indent, pad := shift(row)
+		line.WriteString(fmt.Sprintf("\x1b[2m%3d\x1b[0m %s", row, indent))
+		for col := 0; col < game.Width; col++ {
+			cell := game.Grid[row][col]
+			index := playerIndex(cell)
+			switch {
+			case cell == Empty:
+				line.WriteString("\x1b[2m· \x1b[0m")
+			case cell == Wall:
+				line.WriteString(ansiColor(wallColor, true, 1) + "  \x1b[0m")
+			case cell == Blocked:
+				line.WriteString("\x1b[2m╳ \x1b[0m")
+			case index >= 0:
+				color := PlayerColors[index%len(PlayerColors)]
+				mark := " "
+				if last != nil && last.Player == cell && last.To == (Position{row, col}) {
+					mark = arrows[last.Direction]
+				}
+				brightness := 1.0
+				if !game.ActivePlayers[cell] {
+					brightness = 0.35
+				}
+				line.WriteString(ansiColor(color, true, brightness) + "\x1b[1;30m" + cell + mark + "\x1b[0m")
+			default:
+				for i, trail := range TrailChars {
+					if cell == trail {
+						line.WriteString(ansiColor(PlayerColors[i%len(PlayerColors)], true, 0.45) + "  \x1b[0m")
+					}
+				}
+			}
+		}
+		line.WriteString(pad)
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// tuiPanel lists the players with their state, speed, last answer and the
+// reasoning behind it
+func tuiPanel(game *GameState, gameNumber int, status string) []string {
+	lines := []string{
+		fmt.Sprintf("\x1b[1mGame %d · move %d\x1b[0m  %s", gameNumber, len(game.Moves), status),
+		"",
+	}
+
+	for i := 0; i < game.NumPlayers; i++ {
+		id := PlayerIDs[i]
+		color := ansiColor(PlayerColors[i%len(PlayerColors)], false, 1)
+		lines = append(lines, fmt.Sprintf("%s■\x1b[0m \x1b[1mPlayer %s\x1b[0m  %s",
+			color, id, truncate(game.PlayerConfigs[id].Label(), tuiPanelWidth-12)))
+
+		moves := 0
+		var lastMove *Move
+		for j := range game.Moves {
+			if game.Moves[j].Player == id {
+				moves++
+				lastMove = &game.Moves[j]
+			}
+		}
+		state := "alive"
+		if !game.ActivePlayers[id] {
+			state = fmt.Sprintf("out at move %d", game.EliminatedAt[id])
+		}
+		details := fmt.Sprintf("%s · %d moves", state, moves)
+		if lastMove != nil {
+			response := strings.Join(strings.Fields(lastMove.Response), " ")
+			details += fmt.Sprintf(" · %.2fs · %q", lastMove.Latency, response)
+		}
+		lines = append(lines, "  "+truncate(details, tuiPanelWidth-2))
+		if lastMove != nil && lastMove.Reasoning != "" {
+			for _, line := range wrap(lastMove.Reasoning, tuiPanelWidth-4, tuiReasoningLines) {
+				lines = append(lines, "    \x1b[2m"+line+"\x1b[0m")
+			}
+		}
+	}
+	return lines
+}
+
+// wrap breaks text into at most maxLines lines of width characters at word
+// boundaries, ending in "…" if it is cut short
+func wrap(text string, width, maxLines int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+		if len(lines) == maxLines {
+			lines[maxLines-1] = truncate(lines[maxLines-1]+" …", width)
+			return lines
+		}
+	}
+	if line != "" {
+		lines = append(lines, truncate(line, width))
+	}
+	return lines
+}
+
+// tuiStandings summarizes the statistics so far: wins per model when the
+// models differ, otherwise per seat
+func tuiStandings(stats *GameStats) []string {
+	percent := func(wins, games int) float64 {
+		if games == 0 {
+			return 0
+		}
+		return float64(wins) / float64(games) * 100
+	}
+
+	header := fmt.Sprintf("\x1b[1mStandings\x1b[0m · games played: %d", stats.TotalGames)
+	if stats.Errors > 0 {
+		header += fmt.Sprintf(" · errors: %d", stats.Errors)
+	}
+	lines := []string{header}
+	if len(stats.Models) > 1 {
+		for _, model := range stats.Models {
+			wins, games := stats.ModelWins[model], stats.ModelGames[model]
+			lines = append(lines, truncate(fmt.Sprintf("  %s: %d/%d wins (%.1f%%)",
+				model, wins, games, percent(wins, games)), tuiPanelWidth))
+		}
+		return lines
+	}
+	for i := 0; i < numPlayers; i++ {
+		wins := stats.PlayerWins[PlayerIDs[i]]
+		lines = append(lines, fmt.Sprintf("  Player %s: %d wins (%.1f%%)", PlayerIDs[i], wins, percent(wins, stats.TotalGames)))
+	}
+	return lines
+}
+
+// truncate shortens text to a number of visible characters
+func truncate(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:max(width-1, 0)]) + "…"
+}
+
+// visibleWidth counts the characters of a line without its ANSI escapes
+func visibleWidth(line string) int {
+	width := 0
+	escape := false
+	for _, r := range line {
+		switch {
+		case escape:
+			escape = r != 'm'
+		case r == '\x1b':
+			escape = true
+		default:
+			width++
+		}
+	}
+	return width
+}
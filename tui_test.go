@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		text     string
+		width    int
+		maxLines int
+		want     []string
+	}{
+		{"", 10, 3, nil},
+		{"up is open", 10, 3, []string{"up is open"}},
+		{"up is open\n\nso  go up", 10, 3, []string{"up is open", "so go up"}},
+		{"left is a trap and right is a wall so down it is", 10, 2, []string{"left is a", "trap and …"}},
+		{"unbelievably", 5, 2, []string{"unbe…"}},
+	}
+	for _, tt := range tests {
+		got := wrap(tt.text, tt.width, tt.maxLines)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("wrap(%q, %d, %d) = %q, want %q", tt.text, tt.width, tt.maxLines, got, tt.want)
+		}
+	}
+}
+
+func TestTUIPanelShowsReasoning(t *testing.T) {
+	setup := &GameSetup{
+		Width: 5, Height: 5,
+		StartPositions: []Position{{0, 0}, {4, 4}},
+		Players:        []*PlayerConfig{{Model: "a"}, {Model: "b"}},
+	}
+	game := InitGame(setup)
+	MakeMove(game, "1", Right)
+	game.Moves[0].Reasoning = "Down leads into the corner, right keeps the center open."
+
+	panel := strings.Join(tuiPanel(game, 1, ""), "\n")
+	if !strings.Contains(panel, "right keeps the center open.") {
+		t.Errorf("panel does not show the reasoning:\n%s", panel)
+	}
+}
+
+func TestTUIStandings(t *testing.T) {
+	saved := numPlayers
+	numPlayers = 2
+	defer func() { numPlayers = saved }()
+
+	stats := NewGameStats()
+	stats.TotalGames = 4
+	stats.PlayerWins["1"] = 3
+	stats.Models = []string{"llama3.2"}
+	want := "Standings · games played: 4|  Player 1: 3 wins (75.0%)|  Player 2: 0 wins (0.0%)"
+	if got := visibleStandings(tuiStandings(stats)); got != want {
+		t.Errorf("standings of one model = %q, want %q", got, want)
+	}
+
+	stats.Models = []string{"llama3.2", "mistral"}
+	stats.ModelWins["llama3.2"], stats.ModelGames["llama3.2"] = 3, 4
+	stats.ModelGames["mistral"] = 4
+	stats.Errors = 1
+	want = "Standings · games played: 4 · errors: 1|  llama3.2: 3/4 wins (75.0%)|  mistral: 0/4 wins (0.0%)"
+	if got := visibleStandings(tuiStandings(stats)); got != want {
+		t.Errorf("standings of two models = %q, want %q", got, want)
+	}
+}
+
+// visibleStandings joins panel lines without their ANSI escapes
+func visibleStandings(lines []string) string {
+	for i, line := range lines {
+		lines[i] = strings.NewReplacer("\x1b[1m", "", "\x1b[0m", "").Replace(line)
+	}
+	return strings.Join(lines, "|")
+}